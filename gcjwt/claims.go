@@ -70,10 +70,11 @@
 // 应用层职责：
 //   - 提取 Claims 用于业务逻辑（审计、权限控制）
 //   - 验证 audience 和过期时间（Cloud Run 已验签）
+//   - 未经 Cloud Run 入口的部署（内部负载均衡、Sidecar、GKE）通过 WithSignatureVerification 自行验签
 //
 // 性能特性：
-//   - Token 缓存约 1 小时，自动刷新
-//   - 延迟初始化，避免不必要的开销
+//   - 进程级按 audience 缓存 Token，过期前后台刷新
+//   - 延迟获取，避免不必要的开销
 //   - 线程安全，支持高并发场景
 package gcjwt
 
@@ -93,6 +94,7 @@ type CloudRunClaims struct {
 	IssuedAt        int64  `json:"iat"`
 	ExpiresAt       int64  `json:"exp"`
 	AuthorizedParty string `json:"azp,omitempty"`
+	Issuer          string `json:"iss,omitempty"`
 }
 
 type contextKey struct{}
@@ -158,14 +160,19 @@ func (c *CloudRunClaims) Validate(expectedAudience string) error {
 //   - expectedAudience: 期望的 audience
 //   - logger: Kratos log.Helper 实例
 func (c *CloudRunClaims) ValidateWithLogging(expectedAudience string, logger *log.Helper) error {
+	return c.validateWithSkew(expectedAudience, 0, logger)
+}
+
+// validateWithSkew 在判断过期时容忍 skew 的时钟偏差。
+func (c *CloudRunClaims) validateWithSkew(expectedAudience string, skew time.Duration, logger *log.Helper) error {
 	if expectedAudience != "" && c.Audience != expectedAudience {
 		logger.Warnf("audience mismatch: got=%q want=%q", c.Audience, expectedAudience)
 		return ErrInvalidAudience
 	}
 
-	now := time.Now().Unix()
-	if now >= c.ExpiresAt {
-		logger.Warnf("token expired: exp=%v now=%v", time.Unix(c.ExpiresAt, 0), time.Unix(now, 0))
+	now := time.Now()
+	if now.Add(-skew).Unix() >= c.ExpiresAt {
+		logger.Warnf("token expired: exp=%v now=%v skew=%s", time.Unix(c.ExpiresAt, 0), now, skew)
 		return fmt.Errorf("%w: expired at %v", ErrTokenExpired, time.Unix(c.ExpiresAt, 0))
 	}
 
@@ -177,6 +184,20 @@ func (c *CloudRunClaims) ValidateWithLogging(expectedAudience string, logger *lo
 	return nil
 }
 
+// validateIssuer 检查 iss 是否在允许列表中；列表为空时跳过。
+func (c *CloudRunClaims) validateIssuer(issuers []string, logger *log.Helper) error {
+	if len(issuers) == 0 {
+		return nil
+	}
+	for _, iss := range issuers {
+		if c.Issuer == iss {
+			return nil
+		}
+	}
+	logger.Warnf("issuer mismatch: got=%q want=%q", c.Issuer, issuers)
+	return ErrInvalidIssuer
+}
+
 // NOTE: 如果业务需要允许匿名调用，可结合 WithTokenRequired(false) 并在 handler 中对 ErrMissingEmail 做兼容处理。
 
 // IsExpired 判断 Token 是否已过期。
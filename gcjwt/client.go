@@ -15,6 +15,11 @@ type clientOptions struct {
 	logger    log.Logger
 	headerKey string
 	disabled  bool
+	cache     *TokenCache
+
+	credentialsFile string
+	impersonate     string
+	delegates       []string
 }
 
 // ClientOption 定义客户端中间件配置。
@@ -54,46 +59,86 @@ func WithClientDisabled(disabled bool) ClientOption {
 	}
 }
 
-// Client 返回 Kratos 客户端中间件，为每次调用注入 Cloud Run ID Token。
-func Client(opts ...ClientOption) middleware.Middleware {
-	options := defaultClientOptions()
-	for _, opt := range opts {
-		opt(options)
-	}
-
-	if options.audience == "" {
-		panic("gcjwt: audience is required for client middleware")
-	}
-	if options.logger == nil {
-		options.logger = log.NewStdLogger(io.Discard)
+// WithTokenCache 指定 TokenCache，未指定时使用 DefaultTokenCache。自定义工厂通过
+// NewTokenCache(WithCacheFactory(f)) 创建缓存，由创建方在关闭时调用 Close；
+// testissuer.Issuer.TokenCache 即按此方式提供。
+func WithTokenCache(cache *TokenCache) ClientOption {
+	return func(o *clientOptions) {
+		o.cache = cache
 	}
+}
 
-	helper := log.NewHelper(log.With(options.logger, "middleware", "gcjwt.client"))
-	tokenSource := NewTokenSource(options.audience, options.logger)
+// Client 返回 Kratos 客户端中间件，为每次调用注入 Cloud Run ID Token。
+func Client(opts ...ClientOption) middleware.Middleware {
+	inj := newTokenInjector(opts)
 
 	return func(next middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (interface{}, error) {
-			if options.disabled {
-				helper.Debug("client middleware disabled, skipping token injection")
+			if inj.options.disabled {
+				inj.helper.Debug("client middleware disabled, skipping token injection")
 				return next(ctx, req)
 			}
 
-			token, err := tokenSource.Token(ctx)
+			token, err := inj.token(ctx)
 			if err != nil {
-				helper.Errorf("failed to get id token: %v", err)
-				return nil, fmt.Errorf("gcjwt client: %w", err)
+				return nil, err
 			}
 
 			tr, ok := transport.FromClientContext(ctx)
 			if !ok {
-				helper.Error("transport not found in client context")
+				inj.helper.Error("transport not found in client context")
 				return nil, fmt.Errorf("gcjwt client: transport not available in context")
 			}
 
-			tr.RequestHeader().Set(options.headerKey, "Bearer "+token)
-			helper.Debugf("attached id token (audience=%s)", options.audience)
+			tr.RequestHeader().Set(inj.options.headerKey, "Bearer "+token)
+			inj.helper.Debugf("attached id token (audience=%s)", inj.options.audience)
 
 			return next(ctx, req)
 		}
 	}
 }
+
+// tokenInjector 承载客户端取 Token 流程，供 Kratos、net/http 与 grpc-go 各出口共用。
+type tokenInjector struct {
+	options *clientOptions
+	helper  *log.Helper
+	source  *TokenSource
+}
+
+func newTokenInjector(opts []ClientOption) *tokenInjector {
+	options := defaultClientOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.audience == "" {
+		panic("gcjwt: audience is required for client middleware")
+	}
+	if options.logger == nil {
+		options.logger = log.NewStdLogger(io.Discard)
+	}
+
+	source := NewTokenSource(options.audience, options.logger)
+	switch {
+	case options.cache != nil:
+		source.cache = options.cache
+	case options.credentialsFile != "" || options.impersonate != "":
+		factory := credentialsFactory(options.credentialsFile, options.impersonate, options.delegates)
+		key := identityKey(options.credentialsFile, options.impersonate, options.delegates)
+		source.cache = identityTokenCache(key, factory, options.logger)
+	}
+	return &tokenInjector{
+		options: options,
+		helper:  log.NewHelper(log.With(options.logger, "middleware", "gcjwt.client", "logger", "gcjwt.client")),
+		source:  source,
+	}
+}
+
+func (i *tokenInjector) token(ctx context.Context) (string, error) {
+	token, err := i.source.Token(ctx)
+	if err != nil {
+		i.helper.Errorf("failed to get id token: %v", err)
+		return "", fmt.Errorf("gcjwt client: %w", err)
+	}
+	return token, nil
+}
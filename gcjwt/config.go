@@ -1,12 +1,22 @@
 package gcjwt
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // ClientConfig 定义客户端中间件所需配置。
 type ClientConfig struct {
 	Audience  string `json:"audience" yaml:"audience"`
 	Disabled  bool   `json:"disabled" yaml:"disabled"`
 	HeaderKey string `json:"header_key,omitempty" yaml:"header_key,omitempty"`
+
+	// CredentialsFile 指定凭据 JSON，替代环境 ADC。
+	CredentialsFile string `json:"credentials_file,omitempty" yaml:"credentials_file,omitempty"`
+	// ImpersonateServiceAccount 非空时通过 IAM Credentials 以该服务账号身份获取 ID Token，
+	// Delegates 为委托链。
+	ImpersonateServiceAccount string   `json:"impersonate_service_account,omitempty" yaml:"impersonate_service_account,omitempty"`
+	Delegates                 []string `json:"delegates,omitempty" yaml:"delegates,omitempty"`
 }
 
 // Validate 校验客户端配置。
@@ -14,6 +24,9 @@ func (c *ClientConfig) Validate() error {
 	if !c.Disabled && c.Audience == "" {
 		return fmt.Errorf("client audience is required when middleware is enabled")
 	}
+	if len(c.Delegates) > 0 && c.ImpersonateServiceAccount == "" {
+		return fmt.Errorf("delegates require impersonate_service_account")
+	}
 	return nil
 }
 
@@ -23,6 +36,19 @@ type ServerConfig struct {
 	SkipValidate     bool   `json:"skip_validate" yaml:"skip_validate"`
 	Required         bool   `json:"required" yaml:"required"`
 	HeaderKey        string `json:"header_key,omitempty" yaml:"header_key,omitempty"`
+
+	// VerifySignature 开启 RS256 验签；JWKSFile 优先于 JWKSURL，二者均为空时使用 Google JWKS。
+	VerifySignature bool          `json:"verify_signature" yaml:"verify_signature"`
+	JWKSURL         string        `json:"jwks_url,omitempty" yaml:"jwks_url,omitempty"`
+	JWKSFile        string        `json:"jwks_file,omitempty" yaml:"jwks_file,omitempty"`
+	ClockSkew       time.Duration `json:"clock_skew,omitempty" yaml:"clock_skew,omitempty"`
+	Issuers         []string      `json:"issuers,omitempty" yaml:"issuers,omitempty"`
+
+	// Policies 为空时不做授权，仅认证。
+	Policies *PolicyConfig `json:"policies,omitempty" yaml:"policies,omitempty"`
+
+	// EnrichCaller 将调用方 email/subject 写入 Span 属性与日志 label。
+	EnrichCaller bool `json:"enrich_caller" yaml:"enrich_caller"`
 }
 
 // Validate 校验服务端配置。
@@ -30,6 +56,14 @@ func (c *ServerConfig) Validate() error {
 	if !c.SkipValidate && c.ExpectedAudience == "" {
 		return fmt.Errorf("expected_audience is required when validation is enabled")
 	}
+	if c.ClockSkew < 0 {
+		return fmt.Errorf("clock_skew must not be negative")
+	}
+	if c.Policies != nil {
+		if err := c.Policies.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -37,9 +71,10 @@ func (c *ServerConfig) Validate() error {
 type Config struct {
 	Client *ClientConfig
 	Server *ServerConfig
+	User   *UserConfig
 }
 
 // IsZero 判断配置是否为空。
 func (c Config) IsZero() bool {
-	return c.Client == nil && c.Server == nil
+	return c.Client == nil && c.Server == nil && c.User == nil
 }
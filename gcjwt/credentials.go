@@ -0,0 +1,78 @@
+package gcjwt
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/idtoken"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+// WithCredentialsFile 使用指定的凭据 JSON（服务账号密钥或外部账号配置）获取 ID Token，
+// 替代环境中的 ADC。
+func WithCredentialsFile(path string) ClientOption {
+	return func(o *clientOptions) {
+		o.credentialsFile = path
+	}
+}
+
+// WithImpersonation 通过 IAM Credentials generateIdToken 以 target 服务账号身份获取 ID Token。
+//
+// 调用方（ADC 或 WithCredentialsFile 指定的身份）需要对 delegates 链上第一个账号具有
+// roles/iam.serviceAccountTokenCreator，链上每个账号依次对下一个账号授权，最后一个对 target 授权。
+func WithImpersonation(target string, delegates ...string) ClientOption {
+	return func(o *clientOptions) {
+		o.impersonate = target
+		o.delegates = delegates
+	}
+}
+
+// credentialsFactory 按凭据文件与模拟配置构造工厂；二者均为空时返回 nil，使用默认 ADC 工厂。
+func credentialsFactory(file, target string, delegates []string) TokenSourceFactory {
+	if file == "" && target == "" {
+		return nil
+	}
+	var opts []option.ClientOption
+	if file != "" {
+		opts = append(opts, option.WithCredentialsFile(file))
+	}
+	if target != "" {
+		return func(ctx context.Context, audience string) (oauth2.TokenSource, error) {
+			return impersonate.IDTokenSource(ctx, impersonate.IDTokenConfig{
+				Audience:        audience,
+				TargetPrincipal: target,
+				IncludeEmail:    true,
+				Delegates:       delegates,
+			}, opts...)
+		}
+	}
+	return func(ctx context.Context, audience string) (oauth2.TokenSource, error) {
+		return idtoken.NewTokenSource(ctx, audience, opts...)
+	}
+}
+
+// identityKey 标识凭据组合，相同身份的客户端共享同一 TokenCache。
+func identityKey(file, target string, delegates []string) string {
+	return "file=" + file + ";impersonate=" + target + ";delegates=" + strings.Join(delegates, ",")
+}
+
+var (
+	identityCachesMu sync.Mutex
+	identityCaches   = map[string]*TokenCache{}
+)
+
+// identityTokenCache 返回身份对应的进程级缓存，不同身份的客户端互不干扰。
+func identityTokenCache(key string, factory TokenSourceFactory, logger log.Logger) *TokenCache {
+	identityCachesMu.Lock()
+	defer identityCachesMu.Unlock()
+	if cache, ok := identityCaches[key]; ok {
+		return cache
+	}
+	cache := NewTokenCache(WithCacheFactory(factory), WithCacheLogger(logger))
+	identityCaches[key] = cache
+	return cache
+}
@@ -0,0 +1,63 @@
+package gcjwt
+
+import (
+	"context"
+
+	"github.com/bionicotaku/lingo-utils/gclog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 调用方身份在 Span 属性与日志 label 中使用的键。
+const (
+	CallerEmailAttribute   = "gcjwt.caller.email"
+	CallerSubjectAttribute = "gcjwt.caller.subject"
+	CallerEmailLabel       = "caller_email"
+	CallerSubjectLabel     = "caller_subject"
+)
+
+// WithCallerEnrichment 在认证成功后把调用方 email/subject 写入当前 Span 属性，
+// 并通过 gclog.ContextWithLabels 附加到后续使用该请求上下文记录的日志。
+func WithCallerEnrichment(enabled bool) ServerOption {
+	return func(o *serverOptions) {
+		o.enrichCaller = enabled
+	}
+}
+
+// EnrichCaller 将 claims 中的调用方身份写入 ctx 上的 Span 与日志 label，返回新的上下文。
+// Server 开启 WithCallerEnrichment 时会自动调用，自定义认证流程也可直接使用。
+func EnrichCaller(ctx context.Context, claims *CloudRunClaims) context.Context {
+	if claims == nil {
+		return ctx
+	}
+	attrs := make([]attribute.KeyValue, 0, 2)
+	labels := make(map[string]string, 2)
+	if claims.Email != "" {
+		attrs = append(attrs, attribute.String(CallerEmailAttribute, claims.Email))
+		labels[CallerEmailLabel] = claims.Email
+	}
+	if claims.Subject != "" {
+		attrs = append(attrs, attribute.String(CallerSubjectAttribute, claims.Subject))
+		labels[CallerSubjectLabel] = claims.Subject
+	}
+	if len(attrs) == 0 {
+		return ctx
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	return gclog.ContextWithLabels(ctx, labels)
+}
+
+// Caller 返回上下文中调用方的标识，优先使用 email，缺失时回退到 subject。
+func Caller(ctx context.Context) (string, bool) {
+	claims, ok := FromContext(ctx)
+	if !ok || claims == nil {
+		return "", false
+	}
+	if claims.Email != "" {
+		return claims.Email, true
+	}
+	if claims.Subject != "" {
+		return claims.Subject, true
+	}
+	return "", false
+}
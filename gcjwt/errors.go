@@ -27,6 +27,18 @@ var (
 	// ErrMissingEmail 表示 Token 缺少 email 字段。
 	ErrMissingEmail = kerrors.Unauthorized(errorDomain, "missing email claim")
 
+	// ErrInvalidSignature 表示 Token 签名校验失败（算法不支持、kid 未知或签名不匹配）。
+	ErrInvalidSignature = kerrors.Unauthorized(errorDomain, "invalid token signature")
+
+	// ErrInvalidIssuer 表示 iss 不在允许列表中。
+	ErrInvalidIssuer = kerrors.Unauthorized(errorDomain, "invalid issuer")
+
+	// ErrMissingUserToken 表示要求终端用户身份但请求未携带用户 Token。
+	ErrMissingUserToken = kerrors.Unauthorized(errorDomain, "missing user authorization header")
+
+	// ErrPermissionDenied 表示调用方未通过授权策略。
+	ErrPermissionDenied = kerrors.Forbidden(errorDomain, "caller is not allowed to perform this operation")
+
 	// ErrTokenSourceInit 表示初始化 TokenSource 失败。
 	ErrTokenSourceInit = errors.New("failed to initialize ID token source")
 
@@ -0,0 +1,62 @@
+package gcjwt
+
+import (
+	"net/http"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+// Transport 返回为每个请求注入 ID Token 的 http.RoundTripper，适用于 Kratos 之外的
+// 普通 http.Client 调用；base 为空时使用 http.DefaultTransport。
+//
+//	client := &http.Client{Transport: gcjwt.Transport(nil, gcjwt.WithAudience(aud))}
+func Transport(base http.RoundTripper, opts ...ClientOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &roundTripper{base: base, inj: newTokenInjector(opts)}
+}
+
+type roundTripper struct {
+	base http.RoundTripper
+	inj  *tokenInjector
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.inj.options.disabled {
+		return rt.base.RoundTrip(req)
+	}
+
+	token, err := rt.inj.token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+
+	// RoundTripper 不得修改原请求，克隆后再写 Header。
+	out := req.Clone(req.Context())
+	out.Header.Set(rt.inj.options.headerKey, "Bearer "+token)
+	rt.inj.helper.Debugf("attached id token (audience=%s)", rt.inj.options.audience)
+	return rt.base.RoundTrip(out)
+}
+
+// Handler 包装 http.Handler，按 Server 相同的规则校验 ID Token，
+// 成功后 Claims 可通过 FromContext(r.Context()) 读取。
+//
+// 授权策略以请求路径（r.URL.Path）作为 operation；校验失败时按 Kratos 错误码写回状态码。
+func Handler(next http.Handler, opts ...ServerOption) http.Handler {
+	auth := newAuthenticator(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := headerValue(r.Header.Get, auth.options.headerKey)
+		ctx, err := auth.authenticate(r.Context(), header, r.URL.Path)
+		if err != nil {
+			e := kerrors.FromError(err)
+			http.Error(w, e.Message, int(e.Code))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,277 @@
+package gcjwt
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"golang.org/x/sync/singleflight"
+)
+
+// GoogleJWKSURL 是 Google 签发 ID Token 所用公钥的 JWKS 地址。
+const GoogleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+const (
+	defaultJWKSRefreshInterval = time.Hour
+	defaultJWKSMinRefetch      = 30 * time.Second
+	defaultJWKSFetchTimeout    = 10 * time.Second
+)
+
+// ErrKeyNotFound 表示 KeySource 中找不到 Token 头部声明的 kid。
+var ErrKeyNotFound = errors.New("gcjwt: signing key not found")
+
+// KeySource 按 kid 提供验签所需的 RSA 公钥。
+type KeySource interface {
+	Key(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// StaticKeySource 是固定公钥集合，适用于离线测试与本地 JWKS 文件。
+type StaticKeySource map[string]*rsa.PublicKey
+
+// Key 实现 KeySource。
+func (s StaticKeySource) Key(_ context.Context, kid string) (*rsa.PublicKey, error) {
+	if key, ok := s[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: kid=%q", ErrKeyNotFound, kid)
+}
+
+// ParseJWKS 解析 JWKS 文档，返回其中 RSA 签名公钥。
+func ParseJWKS(data []byte) (StaticKeySource, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("gcjwt: decode jwks: %w", err)
+	}
+	keys := make(StaticKeySource, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		pub, err := k.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("gcjwt: jwk %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
+
+// LoadJWKSFile 从本地文件加载 JWKS，便于离线验签。
+func LoadJWKSFile(path string) (StaticKeySource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gcjwt: read jwks file: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (k jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent: %w", err)
+	}
+	e := new(big.Int).SetBytes(eBytes)
+	if !e.IsInt64() || e.Int64() > 1<<31-1 || e.Int64() < 3 {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(nBytes), E: int(e.Int64())}, nil
+}
+
+// JWKSOption 用于自定义远程 JWKS 行为。
+type JWKSOption func(*JWKSKeySource)
+
+// WithJWKSHTTPClient 指定拉取 JWKS 的 HTTP 客户端。
+func WithJWKSHTTPClient(client *http.Client) JWKSOption {
+	return func(s *JWKSKeySource) {
+		if client != nil {
+			s.client = client
+		}
+	}
+}
+
+// WithJWKSRefreshInterval 指定后台刷新周期，默认 1 小时。
+func WithJWKSRefreshInterval(d time.Duration) JWKSOption {
+	return func(s *JWKSKeySource) {
+		if d > 0 {
+			s.refreshInterval = d
+		}
+	}
+}
+
+// WithJWKSLogger 注入日志器。
+func WithJWKSLogger(logger log.Logger) JWKSOption {
+	return func(s *JWKSKeySource) {
+		if logger != nil {
+			s.logger = log.NewHelper(log.With(logger, "logger", "gcjwt.jwks"))
+		}
+	}
+}
+
+// JWKSKeySource 从远程 JWKS 地址拉取公钥，按 kid 缓存并在后台定期刷新。
+//
+// 特性：
+//   - 首次调用 Key 时拉取并启动后台刷新
+//   - 未知 kid 会触发一次即时刷新（间隔不少于 30s，防止被放大攻击）
+//   - 并发未命中通过 singleflight 合并为一次请求
+type JWKSKeySource struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+	logger          *log.Helper
+
+	mu        sync.RWMutex
+	keys      StaticKeySource
+	fetchedAt time.Time
+
+	group     singleflight.Group
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stop      chan struct{}
+}
+
+var (
+	defaultKeySourceOnce sync.Once
+	defaultKeySource     *JWKSKeySource
+)
+
+// DefaultKeySource 返回开启验签但未指定 KeySource 时共用的 Google JWKS 公钥源，
+// 进程内所有服务端中间件共享同一个后台刷新协程；首次调用时的 logger 生效。
+// 服务关闭时调用其 Close 停止刷新。
+func DefaultKeySource(logger log.Logger) *JWKSKeySource {
+	defaultKeySourceOnce.Do(func() {
+		defaultKeySource = NewJWKSKeySource(GoogleJWKSURL, WithJWKSLogger(logger))
+	})
+	return defaultKeySource
+}
+
+// NewJWKSKeySource 创建远程 JWKS 公钥源。
+func NewJWKSKeySource(url string, opts ...JWKSOption) *JWKSKeySource {
+	s := &JWKSKeySource{
+		url:             url,
+		client:          &http.Client{Timeout: defaultJWKSFetchTimeout},
+		refreshInterval: defaultJWKSRefreshInterval,
+		logger:          log.NewHelper(log.NewStdLogger(io.Discard)),
+		stop:            make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Key 实现 KeySource。
+func (s *JWKSKeySource) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.startOnce.Do(func() { go s.refreshLoop() })
+
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	fetchedAt := s.fetchedAt
+	s.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	if !fetchedAt.IsZero() && time.Since(fetchedAt) < defaultJWKSMinRefetch {
+		return nil, fmt.Errorf("%w: kid=%q", ErrKeyNotFound, kid)
+	}
+	if err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: kid=%q", ErrKeyNotFound, kid)
+}
+
+// Close 停止后台刷新。
+func (s *JWKSKeySource) Close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+func (s *JWKSKeySource) refreshLoop() {
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.refresh(context.Background()); err != nil {
+				s.logger.Warnf("background jwks refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// refresh 合并并发刷新。拉取使用脱离调用方的上下文与独立超时，避免首个调用方取消时
+// 连带使其他等待者失败；调用方上下文只决定自己等待多久。
+func (s *JWKSKeySource) refresh(ctx context.Context) error {
+	ch := s.group.DoChan("refresh", func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultJWKSFetchTimeout)
+		defer cancel()
+		keys, err := s.fetch(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.keys = keys
+		s.fetchedAt = time.Now()
+		s.mu.Unlock()
+		s.logger.Debugf("jwks refreshed (url=%s keys=%d)", s.url, len(keys))
+		return nil, nil
+	})
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-ch:
+		return res.Err
+	}
+}
+
+func (s *JWKSKeySource) fetch(ctx context.Context) (StaticKeySource, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gcjwt: build jwks request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gcjwt: fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gcjwt: fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("gcjwt: read jwks: %w", err)
+	}
+	return ParseJWKS(data)
+}
@@ -0,0 +1,177 @@
+package gcjwt
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// PolicyDeny 表示未命中任何规则时拒绝调用。
+	PolicyDeny = "deny"
+	// PolicyAllow 表示未命中任何规则时放行调用。
+	PolicyAllow = "allow"
+)
+
+// PolicyConfig 声明式授权策略，在 Claims 校验通过后按 Kratos operation 评估。
+//
+// 规则按声明顺序匹配，第一条 Operation 命中的规则决定结果：调用方 email 命中
+// Emails 或 email 域名命中 Domains 则放行，否则拒绝；均未命中时使用 Default。
+type PolicyConfig struct {
+	Default string       `json:"default,omitempty" yaml:"default,omitempty"`
+	Rules   []PolicyRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// PolicyRule 描述单个 operation 的调用方白名单。
+//
+// Operation 与 Emails 支持 glob：`*` 匹配任意字符（包括 `/`），`?` 匹配单个字符；
+// Emails 为 `*` 时表示任意调用方（包括匿名）。
+type PolicyRule struct {
+	Operation string   `json:"operation" yaml:"operation"`
+	Emails    []string `json:"emails,omitempty" yaml:"emails,omitempty"`
+	Domains   []string `json:"domains,omitempty" yaml:"domains,omitempty"`
+}
+
+// Validate 校验策略配置。
+func (c *PolicyConfig) Validate() error {
+	switch c.Default {
+	case "", PolicyDeny, PolicyAllow:
+	default:
+		return fmt.Errorf("policy default must be %q or %q, got %q", PolicyDeny, PolicyAllow, c.Default)
+	}
+	for i, rule := range c.Rules {
+		if rule.Operation == "" {
+			return fmt.Errorf("policy rule %d: operation is required", i)
+		}
+	}
+	return nil
+}
+
+// Decision 是一次授权评估的结果。
+type Decision struct {
+	Allowed bool
+	// Rule 为命中规则的 Operation 模式；未命中任何规则时为 "default"。
+	Rule string
+}
+
+// Policy 是编译后的授权策略，可并发使用。
+type Policy struct {
+	defaultAllow bool
+	rules        []compiledRule
+}
+
+type compiledRule struct {
+	pattern   string
+	operation *regexp.Regexp
+	emails    []*regexp.Regexp
+	domains   []string
+}
+
+// NewPolicy 编译策略配置；Default 为空时按 deny 处理。
+func NewPolicy(cfg PolicyConfig) (*Policy, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	p := &Policy{defaultAllow: cfg.Default == PolicyAllow}
+	for _, rule := range cfg.Rules {
+		cr := compiledRule{
+			pattern:   rule.Operation,
+			operation: compileGlob(rule.Operation),
+		}
+		for _, email := range rule.Emails {
+			cr.emails = append(cr.emails, compileGlob(strings.ToLower(email)))
+		}
+		for _, domain := range rule.Domains {
+			cr.domains = append(cr.domains, strings.ToLower(strings.TrimPrefix(domain, "@")))
+		}
+		p.rules = append(p.rules, cr)
+	}
+	return p, nil
+}
+
+// Authorize 评估调用方 email 能否执行 operation；匿名调用方传空字符串。
+func (p *Policy) Authorize(operation, email string) Decision {
+	email = strings.ToLower(email)
+	for _, rule := range p.rules {
+		if !rule.operation.MatchString(operation) {
+			continue
+		}
+		return Decision{Allowed: rule.allows(email), Rule: rule.pattern}
+	}
+	return Decision{Allowed: p.defaultAllow, Rule: "default"}
+}
+
+func (r compiledRule) allows(email string) bool {
+	for _, pattern := range r.emails {
+		if pattern.MatchString(email) {
+			return true
+		}
+	}
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		domain := email[at+1:]
+		for _, d := range r.domains {
+			if domain == d {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compileGlob 将 glob 转换为整串匹配的正则。
+func compileGlob(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+const (
+	attrOperationKey = "rpc.operation"
+	attrCallerKey    = "gcjwt.caller"
+	attrDecisionKey  = "gcjwt.decision"
+	attrRuleKey      = "gcjwt.rule"
+)
+
+// authzMetrics 记录授权决策计数。
+type authzMetrics struct {
+	decisions metric.Int64Counter
+}
+
+func newAuthzMetrics(meter metric.Meter) (*authzMetrics, error) {
+	counter, err := meter.Int64Counter("gcjwt_authz_decision_total")
+	if err != nil {
+		return nil, err
+	}
+	return &authzMetrics{decisions: counter}, nil
+}
+
+func (m *authzMetrics) record(ctx context.Context, operation, caller string, d Decision) {
+	if m == nil {
+		return
+	}
+	decision := PolicyDeny
+	if d.Allowed {
+		decision = PolicyAllow
+	}
+	m.decisions.Add(ctx, 1, metric.WithAttributes(
+		attribute.String(attrOperationKey, operation),
+		attribute.String(attrCallerKey, caller),
+		attribute.String(attrDecisionKey, decision),
+		attribute.String(attrRuleKey, d.Rule),
+	))
+}
@@ -14,18 +14,22 @@ type ClientMiddleware middleware.Middleware
 // ServerMiddleware 标识入站（被调方）JWT 中间件。
 type ServerMiddleware middleware.Middleware
 
+// UserMiddleware 标识入站终端用户 Token 中间件。
+type UserMiddleware middleware.Middleware
+
 // Component 聚合客户端与服务端中间件实例。
 type Component struct {
 	client middleware.Middleware
 	server middleware.Middleware
+	user   middleware.Middleware
 }
 
 // NewComponent 根据配置构造 JWT 中间件能力。
 //
 // 行为：
-//   - 当 cfg.Client/Server 为空时，保持对应中间件为 nil；
-//   - 校验每侧配置并包装成 gcjwt.Client/Server；
-//   - 返回 no-op cleanup，预留未来扩展。
+//   - 当 cfg.Client/Server/User 为空时，保持对应中间件为 nil；
+//   - 校验每侧配置并包装成 gcjwt.Client/Server/User；
+//   - cleanup 负责停止验签与终端用户校验的 JWKS 后台刷新，未启用时为 no-op。
 func NewComponent(cfg Config, logger log.Logger) (*Component, func(), error) {
 	if cfg.IsZero() {
 		return &Component{}, func() {}, nil
@@ -35,9 +39,16 @@ func NewComponent(cfg Config, logger log.Logger) (*Component, func(), error) {
 	}
 
 	var (
-		client middleware.Middleware
-		server middleware.Middleware
+		client   middleware.Middleware
+		server   middleware.Middleware
+		user     middleware.Middleware
+		cleanups []func()
 	)
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
 
 	if cfg.Client != nil {
 		if err := cfg.Client.Validate(); err != nil {
@@ -55,6 +66,12 @@ func NewComponent(cfg Config, logger log.Logger) (*Component, func(), error) {
 		if cfg.Client.Disabled {
 			clientOpts = append(clientOpts, WithClientDisabled(true))
 		}
+		if cfg.Client.CredentialsFile != "" {
+			clientOpts = append(clientOpts, WithCredentialsFile(cfg.Client.CredentialsFile))
+		}
+		if cfg.Client.ImpersonateServiceAccount != "" {
+			clientOpts = append(clientOpts, WithImpersonation(cfg.Client.ImpersonateServiceAccount, cfg.Client.Delegates...))
+		}
 		client = Client(clientOpts...)
 	}
 
@@ -66,6 +83,7 @@ func NewComponent(cfg Config, logger log.Logger) (*Component, func(), error) {
 			WithServerLogger(logger),
 			WithSkipValidate(cfg.Server.SkipValidate),
 			WithTokenRequired(cfg.Server.Required),
+			WithCallerEnrichment(cfg.Server.EnrichCaller),
 		}
 		if cfg.Server.ExpectedAudience != "" {
 			serverOpts = append(serverOpts, WithExpectedAudience(cfg.Server.ExpectedAudience))
@@ -73,15 +91,67 @@ func NewComponent(cfg Config, logger log.Logger) (*Component, func(), error) {
 		if cfg.Server.HeaderKey != "" {
 			serverOpts = append(serverOpts, WithServerHeaderKey(cfg.Server.HeaderKey))
 		}
+		if cfg.Server.ClockSkew > 0 {
+			serverOpts = append(serverOpts, WithClockSkew(cfg.Server.ClockSkew))
+		}
+		if cfg.Server.Policies != nil {
+			policy, err := NewPolicy(*cfg.Server.Policies)
+			if err != nil {
+				return nil, nil, fmt.Errorf("gcjwt: invalid policies: %w", err)
+			}
+			serverOpts = append(serverOpts, WithPolicy(policy))
+		}
+		if cfg.Server.VerifySignature {
+			keys, closeKeys, err := newKeySource(cfg.Server, logger)
+			if err != nil {
+				return nil, nil, fmt.Errorf("gcjwt: init key source: %w", err)
+			}
+			cleanups = append(cleanups, closeKeys)
+			serverOpts = append(serverOpts,
+				WithSignatureVerification(true),
+				WithKeySource(keys),
+			)
+			if len(cfg.Server.Issuers) > 0 {
+				serverOpts = append(serverOpts, WithAllowedIssuers(cfg.Server.Issuers...))
+			}
+		}
 		server = Server(serverOpts...)
 	}
 
+	if cfg.User != nil {
+		verifier, err := NewOIDCVerifier(*cfg.User, WithOIDCLogger(logger))
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("gcjwt: invalid user config: %w", err)
+		}
+		cleanups = append(cleanups, verifier.Close)
+		user = User(verifier)
+	}
+
 	comp := &Component{
 		client: client,
 		server: server,
+		user:   user,
 	}
 
-	return comp, func() {}, nil
+	return comp, cleanup, nil
+}
+
+// newKeySource 按配置构造验签公钥源：本地 JWKS 文件优先，其次远程 JWKS。
+func newKeySource(cfg *ServerConfig, logger log.Logger) (KeySource, func(), error) {
+	if cfg.JWKSFile != "" {
+		keys, err := LoadJWKSFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		return keys, func() {}, nil
+	}
+	url := cfg.JWKSURL
+	if url == "" {
+		url = GoogleJWKSURL
+	}
+	keys := NewJWKSKeySource(url, WithJWKSLogger(logger))
+	return keys, keys.Close, nil
 }
 
 // ProvideClientMiddleware 暴露客户端中间件；当未启用时返回 nil。
@@ -100,9 +170,18 @@ func ProvideServerMiddleware(comp *Component) (ServerMiddleware, error) {
 	return ServerMiddleware(comp.server), nil
 }
 
+// ProvideUserMiddleware 暴露终端用户 Token 中间件；当未启用时返回 nil。
+func ProvideUserMiddleware(comp *Component) (UserMiddleware, error) {
+	if comp == nil || comp.user == nil {
+		return nil, nil
+	}
+	return UserMiddleware(comp.user), nil
+}
+
 // ProviderSet 供 Wire 使用，统一注入组件与中间件。
 var ProviderSet = wire.NewSet(
 	NewComponent,
 	ProvideClientMiddleware,
 	ProvideServerMiddleware,
+	ProvideUserMiddleware,
 )
@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
 )
 
 type serverOptions struct {
@@ -19,6 +22,13 @@ type serverOptions struct {
 	headerKey        string
 	skipValidate     bool
 	required         bool
+	verifySignature  bool
+	keySource        KeySource
+	clockSkew        time.Duration
+	issuers          []string
+	policy           *Policy
+	meter            metric.Meter
+	enrichCaller     bool
 }
 
 // ServerOption 用于自定义服务器端中间件行为。
@@ -66,8 +76,79 @@ func WithTokenRequired(required bool) ServerOption {
 	}
 }
 
+// WithSignatureVerification 开启 RS256 验签，适用于未经 Cloud Run 入口验签的部署
+// （内部负载均衡、Sidecar、GKE 等）。未指定 KeySource 时使用共享的 DefaultKeySource，
+// 未指定 issuer 时仅接受 Google 签发的 Token。
+func WithSignatureVerification(enabled bool) ServerOption {
+	return func(o *serverOptions) {
+		o.verifySignature = enabled
+	}
+}
+
+// WithKeySource 指定验签公钥来源，例如 StaticKeySource 或自定义 JWKS 地址。
+func WithKeySource(keys KeySource) ServerOption {
+	return func(o *serverOptions) {
+		o.keySource = keys
+	}
+}
+
+// WithClockSkew 指定判断过期时容忍的时钟偏差。
+func WithClockSkew(skew time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.clockSkew = skew
+	}
+}
+
+// WithAllowedIssuers 指定允许的 iss；开启验签时默认为 GoogleIssuers。
+func WithAllowedIssuers(issuers ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.issuers = issuers
+	}
+}
+
+// WithPolicy 在 Claims 校验通过后按 operation 执行授权，拒绝时返回 ErrPermissionDenied。
+func WithPolicy(policy *Policy) ServerOption {
+	return func(o *serverOptions) {
+		o.policy = policy
+	}
+}
+
+// WithServerMeter 指定记录授权决策指标的 Meter，默认使用全局 MeterProvider。
+func WithServerMeter(meter metric.Meter) ServerOption {
+	return func(o *serverOptions) {
+		o.meter = meter
+	}
+}
+
 // Server 返回 Kratos 服务端中间件，对 Cloud Run ID Token 进行解析与基础校验。
 func Server(opts ...ServerOption) middleware.Middleware {
+	auth := newAuthenticator(opts)
+
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var header, operation string
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				header = headerValue(tr.RequestHeader().Get, auth.options.headerKey)
+				operation = tr.Operation()
+			}
+
+			ctx, err := auth.authenticate(ctx, header, operation)
+			if err != nil {
+				return nil, err
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// authenticator 承载服务端校验流程，供 Kratos、net/http 与 grpc-go 各入口共用。
+type authenticator struct {
+	options *serverOptions
+	helper  *log.Helper
+	authz   *authzMetrics
+}
+
+func newAuthenticator(opts []ServerOption) *authenticator {
 	options := defaultServerOptions()
 	for _, opt := range opts {
 		opt(options)
@@ -76,41 +157,98 @@ func Server(opts ...ServerOption) middleware.Middleware {
 	if options.logger == nil {
 		options.logger = log.NewStdLogger(io.Discard)
 	}
-	helper := log.NewHelper(log.With(options.logger, "middleware", "gcjwt.server"))
+	helper := log.NewHelper(log.With(options.logger, "middleware", "gcjwt.server", "logger", "gcjwt.server"))
 
-	return func(next middleware.Handler) middleware.Handler {
-		return func(ctx context.Context, req interface{}) (interface{}, error) {
-			token, err := extractToken(ctx, options.headerKey)
-			if err != nil {
-				if options.skipValidate {
-					helper.Warnf("skip validate enabled, ignore token error: %v", err)
-					return next(ctx, req)
-				}
-				if !options.required {
-					helper.Debugf("token not required, bypassing error: %v", err)
-					return next(ctx, req)
-				}
+	if options.verifySignature {
+		if options.keySource == nil {
+			options.keySource = DefaultKeySource(options.logger)
+		}
+		if len(options.issuers) == 0 {
+			options.issuers = GoogleIssuers
+		}
+	}
+
+	a := &authenticator{options: options, helper: helper}
+	if options.policy != nil {
+		if options.meter == nil {
+			options.meter = otel.GetMeterProvider().Meter("lingo-utils/gcjwt")
+		}
+		var err error
+		if a.authz, err = newAuthzMetrics(options.meter); err != nil {
+			helper.Warnw("msg", "gcjwt: register authz_decision_total", "err", err)
+		}
+	}
+	return a
+}
+
+// authenticate 校验原始 Header 值，成功时返回写入 Claims 的上下文。
+func (a *authenticator) authenticate(ctx context.Context, header, operation string) (context.Context, error) {
+	options, helper := a.options, a.helper
+
+	token, err := bearerToken(header)
+	if err != nil {
+		if options.skipValidate {
+			helper.Warnf("skip validate enabled, ignore token error: %v", err)
+			// 跳过校验只放宽 Token 本身，授权策略仍按匿名调用方执行。
+			if err := a.authorize(ctx, operation, ""); err != nil {
 				return nil, err
 			}
-
-			claims, err := parseTokenClaims(token)
-			if err != nil {
-				helper.Errorf("parse token claims: %v", err)
-				return nil, ErrTokenParseFail
+			return ctx, nil
+		}
+		if !options.required {
+			helper.Debugf("token not required, bypassing error: %v", err)
+			if err := a.authorize(ctx, operation, ""); err != nil {
+				return nil, err
 			}
+			return ctx, nil
+		}
+		return nil, err
+	}
 
-			if !options.skipValidate {
-				if err := claims.ValidateWithLogging(options.expectedAudience, helper); err != nil {
-					return nil, err
-				}
-			}
+	if options.verifySignature && !options.skipValidate {
+		if err := verifyTokenSignature(ctx, token, options.keySource); err != nil {
+			helper.Warnf("verify token signature: %v", err)
+			return nil, ErrInvalidSignature
+		}
+	}
 
-			ctx = NewContext(ctx, claims)
-			helper.Debugf("authenticated request from email=%s aud=%s", claims.Email, claims.Audience)
+	claims, err := parseTokenClaims(token)
+	if err != nil {
+		helper.Errorf("parse token claims: %v", err)
+		return nil, ErrTokenParseFail
+	}
 
-			return next(ctx, req)
+	if !options.skipValidate {
+		if err := claims.validateWithSkew(options.expectedAudience, options.clockSkew, helper); err != nil {
+			return nil, err
 		}
+		if err := claims.validateIssuer(options.issuers, helper); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.authorize(ctx, operation, claims.Email); err != nil {
+		return nil, err
 	}
+
+	helper.Debugf("authenticated request from email=%s aud=%s", claims.Email, claims.Audience)
+	if options.enrichCaller {
+		ctx = EnrichCaller(ctx, claims)
+	}
+	return NewContext(ctx, claims), nil
+}
+
+func (a *authenticator) authorize(ctx context.Context, operation, email string) error {
+	if a.options.policy == nil {
+		return nil
+	}
+	decision := a.options.policy.Authorize(operation, email)
+	a.authz.record(ctx, operation, email, decision)
+	if !decision.Allowed {
+		a.helper.Warnf("authorization denied: operation=%s caller=%q rule=%s", operation, email, decision.Rule)
+		return ErrPermissionDenied
+	}
+	a.helper.Debugf("authorization allowed: operation=%s caller=%q rule=%s", operation, email, decision.Rule)
+	return nil
 }
 
 func extractToken(ctx context.Context, headerKey string) (string, error) {
@@ -118,11 +256,19 @@ func extractToken(ctx context.Context, headerKey string) (string, error) {
 	if !ok {
 		return "", ErrMissingToken
 	}
+	return bearerToken(headerValue(tr.RequestHeader().Get, headerKey))
+}
 
-	header := tr.RequestHeader().Get(headerKey)
+// headerValue 读取 Header，authorization 缺失时回退到 X-Serverless-Authorization。
+func headerValue(get func(string) string, headerKey string) string {
+	header := get(headerKey)
 	if header == "" && strings.EqualFold(headerKey, "authorization") {
-		header = tr.RequestHeader().Get("x-serverless-authorization")
+		header = get("x-serverless-authorization")
 	}
+	return header
+}
+
+func bearerToken(header string) (string, error) {
 	if header == "" {
 		return "", ErrMissingToken
 	}
@@ -0,0 +1,111 @@
+package gcjwt_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bionicotaku/lingo-utils/gcjwt"
+	"github.com/bionicotaku/lingo-utils/gcjwt/testissuer"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+// writeServiceAccountKey 生成指向本地 token 端点的服务账号密钥文件。
+func writeServiceAccountKey(t *testing.T, email, tokenURI string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "test-project",
+		"private_key_id": "test-key",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   email,
+		"client_id":      "1234567890",
+		"token_uri":      tokenURI,
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), strings.SplitN(email, "@", 2)[0]+".json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestClientCredentialsFileIdentities(t *testing.T) {
+	t.Parallel()
+
+	iss, err := testissuer.New()
+	require.NoError(t, err)
+
+	// 模拟 Google OAuth token 端点：按断言中的 iss（服务账号 email）签发 ID Token。
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assertion := r.PostForm.Get("assertion")
+		parts := strings.Split(assertion, ".")
+		require.Len(t, parts, 3)
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		var claims struct {
+			Iss            string `json:"iss"`
+			TargetAudience string `json:"target_audience"`
+		}
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		token, err := iss.Mint(claims.Iss, claims.TargetAudience)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id_token": token, "expires_in": 3600})
+	}))
+	t.Cleanup(tokenSrv.Close)
+
+	const aud = "https://service-b.run.app/"
+	logger := log.NewStdLogger(io.Discard)
+	fileA := writeServiceAccountKey(t, "svc-a@test-project.iam.gserviceaccount.com", tokenSrv.URL)
+	fileB := writeServiceAccountKey(t, "svc-b@test-project.iam.gserviceaccount.com", tokenSrv.URL)
+
+	server := gcjwt.Server(
+		gcjwt.WithExpectedAudience(aud),
+		gcjwt.WithServerLogger(logger),
+		gcjwt.WithSignatureVerification(true),
+		gcjwt.WithKeySource(iss.KeySource()),
+	)
+
+	for file, email := range map[string]string{
+		fileA: "svc-a@test-project.iam.gserviceaccount.com",
+		fileB: "svc-b@test-project.iam.gserviceaccount.com",
+	} {
+		comp, cleanup, err := gcjwt.NewComponent(gcjwt.Config{
+			Client: &gcjwt.ClientConfig{Audience: aud, CredentialsFile: file},
+		}, logger)
+		require.NoError(t, err)
+		t.Cleanup(cleanup)
+		client, err := gcjwt.ProvideClientMiddleware(comp)
+		require.NoError(t, err)
+
+		claims, err := chain(t, middleware.Middleware(client), server)
+		require.NoError(t, err)
+		require.Equal(t, email, claims.Email)
+	}
+}
+
+func TestClientConfigDelegatesRequireTarget(t *testing.T) {
+	t.Parallel()
+
+	cfg := gcjwt.ClientConfig{Audience: "https://service", Delegates: []string{"a@p.iam.gserviceaccount.com"}}
+	require.Error(t, cfg.Validate())
+
+	cfg.ImpersonateServiceAccount = "target@p.iam.gserviceaccount.com"
+	require.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,91 @@
+package gcjwt_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/bionicotaku/lingo-utils/gcjwt"
+	"github.com/bionicotaku/lingo-utils/gcjwt/testissuer"
+	"github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestServerCallerEnrichment(t *testing.T) {
+	t.Parallel()
+
+	iss, err := testissuer.New()
+	require.NoError(t, err)
+	const aud = "https://service-b.run.app/"
+	token, err := iss.MintClaims(testissuer.Claims{Email: "svc-a@example.com", Subject: "1234567890", Audience: aud})
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	run := func(enabled bool) (map[string]string, map[string]any) {
+		logger, out, err := gclog.NewTestLogger(gclog.WithService("svc-b"), gclog.WithVersion("v1"), gclog.DisableInstanceID())
+		require.NoError(t, err)
+		// 与 gclog.NewComponent 相同：从请求上下文读取 label。
+		logger = log.With(logger, "labels", log.Valuer(func(ctx context.Context) any { return gclog.LabelsFromContext(ctx) }))
+
+		server := gcjwt.Server(
+			gcjwt.WithExpectedAudience(aud),
+			gcjwt.WithServerLogger(log.NewStdLogger(io.Discard)),
+			gcjwt.WithSignatureVerification(true),
+			gcjwt.WithKeySource(iss.KeySource()),
+			gcjwt.WithCallerEnrichment(enabled),
+		)
+		header := newMockHeader()
+		header.Set("authorization", "Bearer "+token)
+		ctx, span := tracer.Start(context.Background(), "request")
+		ctx = transport.NewServerContext(ctx, &mockServerTransport{header: header})
+		_, err = server(func(ctx context.Context, _ interface{}) (interface{}, error) {
+			log.NewHelper(logger).WithContext(ctx).Info("handled")
+			return "ok", nil
+		})(ctx, "req")
+		require.NoError(t, err)
+		span.End()
+
+		spans := recorder.Ended()
+		attrs := map[string]string{}
+		for _, kv := range spans[len(spans)-1].Attributes() {
+			attrs[string(kv.Key)] = kv.Value.AsString()
+		}
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+		labels, _ := entry["labels"].(map[string]any)
+		return attrs, labels
+	}
+
+	attrs, labels := run(true)
+	require.Equal(t, "svc-a@example.com", attrs[gcjwt.CallerEmailAttribute])
+	require.Equal(t, "1234567890", attrs[gcjwt.CallerSubjectAttribute])
+	require.Equal(t, "svc-a@example.com", labels[gcjwt.CallerEmailLabel])
+	require.Equal(t, "1234567890", labels[gcjwt.CallerSubjectLabel])
+
+	attrs, labels = run(false)
+	require.NotContains(t, attrs, gcjwt.CallerEmailAttribute, "enrichment is opt-in")
+	require.NotContains(t, labels, gcjwt.CallerEmailLabel)
+}
+
+func TestCallerFallsBackToSubject(t *testing.T) {
+	t.Parallel()
+
+	_, ok := gcjwt.Caller(context.Background())
+	require.False(t, ok)
+
+	ctx := gcjwt.NewContext(context.Background(), &gcjwt.CloudRunClaims{Subject: "1234567890"})
+	caller, ok := gcjwt.Caller(ctx)
+	require.True(t, ok)
+	require.Equal(t, "1234567890", caller)
+
+	ctx = gcjwt.NewContext(context.Background(), &gcjwt.CloudRunClaims{Email: "svc-a@example.com", Subject: "1234567890"})
+	caller, _ = gcjwt.Caller(ctx)
+	require.Equal(t, "svc-a@example.com", caller)
+}
@@ -42,11 +42,17 @@ func (m *mockClientTransport) RequestHeader() transport.Header { return m.header
 func (m *mockClientTransport) ReplyHeader() transport.Header   { return newMockHeader() }
 
 type mockServerTransport struct {
-	header *mockHeader
+	header    *mockHeader
+	operation string
 }
 
-func (m *mockServerTransport) Kind() transport.Kind            { return transport.KindGRPC }
-func (m *mockServerTransport) Endpoint() string                { return "test" }
-func (m *mockServerTransport) Operation() string               { return "op" }
+func (m *mockServerTransport) Kind() transport.Kind { return transport.KindGRPC }
+func (m *mockServerTransport) Endpoint() string     { return "test" }
+func (m *mockServerTransport) Operation() string {
+	if m.operation == "" {
+		return "op"
+	}
+	return m.operation
+}
 func (m *mockServerTransport) RequestHeader() transport.Header { return m.header }
 func (m *mockServerTransport) ReplyHeader() transport.Header   { return newMockHeader() }
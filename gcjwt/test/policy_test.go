@@ -0,0 +1,145 @@
+package gcjwt_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bionicotaku/lingo-utils/gcjwt"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestPolicyAuthorize(t *testing.T) {
+	t.Parallel()
+
+	policy, err := gcjwt.NewPolicy(gcjwt.PolicyConfig{
+		Rules: []gcjwt.PolicyRule{
+			{Operation: "/grpc.health.v1.Health/*", Emails: []string{"*"}},
+			{Operation: "/orders.v1.Orders/Get*", Domains: []string{"reader.iam.gserviceaccount.com"}},
+			{Operation: "/orders.v1.Orders/*", Emails: []string{"writer@project.iam.gserviceaccount.com"}},
+		},
+	})
+	require.NoError(t, err)
+
+	cases := []struct {
+		operation string
+		email     string
+		allowed   bool
+		rule      string
+	}{
+		{"/grpc.health.v1.Health/Check", "", true, "/grpc.health.v1.Health/*"},
+		{"/orders.v1.Orders/GetOrder", "svc@reader.iam.gserviceaccount.com", true, "/orders.v1.Orders/Get*"},
+		{"/orders.v1.Orders/GetOrder", "Writer@project.iam.gserviceaccount.com", false, "/orders.v1.Orders/Get*"},
+		{"/orders.v1.Orders/CreateOrder", "writer@project.iam.gserviceaccount.com", true, "/orders.v1.Orders/*"},
+		{"/orders.v1.Orders/CreateOrder", "svc@reader.iam.gserviceaccount.com", false, "/orders.v1.Orders/*"},
+		{"/billing.v1.Billing/Charge", "writer@project.iam.gserviceaccount.com", false, "default"},
+	}
+	for _, tc := range cases {
+		d := policy.Authorize(tc.operation, tc.email)
+		require.Equal(t, tc.allowed, d.Allowed, "%s by %s", tc.operation, tc.email)
+		require.Equal(t, tc.rule, d.Rule)
+	}
+
+	allowAll, err := gcjwt.NewPolicy(gcjwt.PolicyConfig{Default: gcjwt.PolicyAllow})
+	require.NoError(t, err)
+	require.True(t, allowAll.Authorize("/any/Op", "svc@example.com").Allowed)
+
+	_, err = gcjwt.NewPolicy(gcjwt.PolicyConfig{Default: "maybe"})
+	require.Error(t, err)
+}
+
+func TestServerMiddlewarePolicy(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+
+	policy, err := gcjwt.NewPolicy(gcjwt.PolicyConfig{
+		Rules: []gcjwt.PolicyRule{{Operation: "/orders.v1.Orders/*", Emails: []string{"svc-a@example.com"}}},
+	})
+	require.NoError(t, err)
+
+	mw := gcjwt.Server(
+		gcjwt.WithExpectedAudience("https://service"),
+		gcjwt.WithServerLogger(log.NewStdLogger(io.Discard)),
+		gcjwt.WithPolicy(policy),
+		gcjwt.WithServerMeter(meter),
+	)
+	call := func(email string) error {
+		header := newMockHeader()
+		header.Set("authorization", "Bearer "+makeToken(t, "https://service", email, time.Now().Add(time.Hour)))
+		ctx := transport.NewServerContext(context.Background(), &mockServerTransport{
+			header:    header,
+			operation: "/orders.v1.Orders/CreateOrder",
+		})
+		_, err := mw(dummyNext)(ctx, "req")
+		return err
+	}
+
+	require.NoError(t, call("svc-a@example.com"))
+	err = call("svc-b@example.com")
+	require.ErrorIs(t, err, gcjwt.ErrPermissionDenied)
+	require.True(t, kerrors.IsForbidden(err))
+
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &data))
+	counts := map[string]int64{}
+	for _, sm := range data.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "gcjwt_authz_decision_total" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				caller, _ := dp.Attributes.Value(attribute.Key("gcjwt.caller"))
+				decision, _ := dp.Attributes.Value(attribute.Key("gcjwt.decision"))
+				counts[caller.AsString()+"/"+decision.AsString()] += dp.Value
+			}
+		}
+	}
+	require.Equal(t, map[string]int64{
+		"svc-a@example.com/allow": 1,
+		"svc-b@example.com/deny":  1,
+	}, counts)
+}
+
+func TestServerMiddlewarePolicyWithSkipValidate(t *testing.T) {
+	t.Parallel()
+
+	policy, err := gcjwt.NewPolicy(gcjwt.PolicyConfig{
+		Rules: []gcjwt.PolicyRule{{Operation: "/orders.v1.Orders/*", Emails: []string{"svc-a@example.com"}}},
+	})
+	require.NoError(t, err)
+
+	mw := gcjwt.Server(
+		gcjwt.WithExpectedAudience("https://service"),
+		gcjwt.WithServerLogger(log.NewStdLogger(io.Discard)),
+		gcjwt.WithSkipValidate(true),
+		gcjwt.WithPolicy(policy),
+		gcjwt.WithServerMeter(sdkmetric.NewMeterProvider().Meter("test")),
+	)
+	call := func(authorization string) error {
+		header := newMockHeader()
+		if authorization != "" {
+			header.Set("authorization", authorization)
+		}
+		ctx := transport.NewServerContext(context.Background(), &mockServerTransport{
+			header:    header,
+			operation: "/orders.v1.Orders/CreateOrder",
+		})
+		_, err := mw(dummyNext)(ctx, "req")
+		return err
+	}
+
+	// 过期 Token 在 skip validate 下被放行，但授权策略仍然生效。
+	expired := time.Now().Add(-time.Hour)
+	require.NoError(t, call("Bearer "+makeToken(t, "https://service", "svc-a@example.com", expired)))
+	require.ErrorIs(t, call("Bearer "+makeToken(t, "https://service", "svc-b@example.com", expired)), gcjwt.ErrPermissionDenied)
+	require.ErrorIs(t, call(""), gcjwt.ErrPermissionDenied)
+}
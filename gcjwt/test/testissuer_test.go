@@ -0,0 +1,138 @@
+package gcjwt_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bionicotaku/lingo-utils/gcjwt"
+	"github.com/bionicotaku/lingo-utils/gcjwt/testissuer"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/require"
+)
+
+// chain 模拟一次 client → server 调用：客户端中间件写入的 Header 直接交给服务端中间件。
+func chain(t *testing.T, client, server middleware.Middleware) (*gcjwt.CloudRunClaims, error) {
+	t.Helper()
+	var got *gcjwt.CloudRunClaims
+	handler := server(func(ctx context.Context, _ interface{}) (interface{}, error) {
+		got, _ = gcjwt.FromContext(ctx)
+		return "ok", nil
+	})
+
+	header := newMockHeader()
+	clientCtx := transport.NewClientContext(context.Background(), &mockClientTransport{header: header})
+	_, err := client(func(context.Context, interface{}) (interface{}, error) {
+		serverCtx := transport.NewServerContext(context.Background(), &mockServerTransport{header: header})
+		return handler(serverCtx, "req")
+	})(clientCtx, "req")
+	return got, err
+}
+
+func TestTestIssuerEndToEnd(t *testing.T) {
+	t.Parallel()
+
+	iss, err := testissuer.New()
+	require.NoError(t, err)
+	t.Cleanup(iss.Close)
+
+	const aud = "https://service-b.run.app/"
+	logger := log.NewStdLogger(io.Discard)
+	keys := gcjwt.NewJWKSKeySource(iss.JWKSURL())
+	t.Cleanup(keys.Close)
+
+	client := gcjwt.Client(
+		gcjwt.WithAudience(aud),
+		gcjwt.WithClientLogger(logger),
+		gcjwt.WithTokenCache(iss.TokenCache("svc-a@project.iam.gserviceaccount.com")),
+	)
+	server := gcjwt.Server(
+		gcjwt.WithExpectedAudience(aud),
+		gcjwt.WithServerLogger(logger),
+		gcjwt.WithSignatureVerification(true),
+		gcjwt.WithKeySource(keys),
+	)
+
+	claims, err := chain(t, client, server)
+	require.NoError(t, err)
+	require.Equal(t, "svc-a@project.iam.gserviceaccount.com", claims.Email)
+	require.Equal(t, testissuer.DefaultIssuer, claims.Issuer)
+
+	// 另一个 Issuer 签发的 Token 无法通过同一 JWKS 验签。
+	other, err := testissuer.New()
+	require.NoError(t, err)
+	t.Cleanup(other.Close)
+	intruder := gcjwt.Client(
+		gcjwt.WithAudience(aud),
+		gcjwt.WithClientLogger(logger),
+		gcjwt.WithTokenCache(other.TokenCache("svc-a@project.iam.gserviceaccount.com")),
+	)
+	_, err = chain(t, intruder, server)
+	require.ErrorIs(t, err, gcjwt.ErrInvalidSignature)
+}
+
+func TestTestIssuerMintClaims(t *testing.T) {
+	t.Parallel()
+
+	iss, err := testissuer.New()
+	require.NoError(t, err)
+
+	const aud = "https://service"
+	expired, err := iss.MintClaims(testissuer.Claims{Email: "svc@example.com", Audience: aud, TTL: -time.Minute})
+	require.NoError(t, err)
+
+	opts := []gcjwt.ServerOption{
+		gcjwt.WithExpectedAudience(aud),
+		gcjwt.WithSignatureVerification(true),
+		gcjwt.WithKeySource(iss.KeySource()),
+	}
+	require.ErrorIs(t, callServer(expired, opts...), gcjwt.ErrTokenExpired)
+
+	wrongAud, err := iss.Mint("svc@example.com", "https://other")
+	require.NoError(t, err)
+	require.ErrorIs(t, callServer(wrongAud, opts...), gcjwt.ErrInvalidAudience)
+}
+
+func TestTestIssuerJWKSFile(t *testing.T) {
+	t.Parallel()
+
+	iss, err := testissuer.New()
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, iss.JWKS(), 0o600))
+
+	keys, err := gcjwt.LoadJWKSFile(path)
+	require.NoError(t, err)
+
+	token, err := iss.Mint("svc@example.com", "https://service")
+	require.NoError(t, err)
+	require.NoError(t, callServer(token,
+		gcjwt.WithExpectedAudience("https://service"),
+		gcjwt.WithSignatureVerification(true),
+		gcjwt.WithKeySource(keys),
+	))
+}
+
+func TestTestIssuerTokenCachePerIdentity(t *testing.T) {
+	t.Parallel()
+
+	iss, err := testissuer.New()
+	require.NoError(t, err)
+	t.Cleanup(iss.Close)
+
+	a := iss.TokenCache("svc-a@example.com")
+	require.Same(t, a, iss.TokenCache("svc-a@example.com"))
+	b := iss.TokenCache("svc-b@example.com")
+	require.NotSame(t, a, b)
+
+	tokenA, err := a.Token(context.Background(), "https://service")
+	require.NoError(t, err)
+	tokenB, err := b.Token(context.Background(), "https://service")
+	require.NoError(t, err)
+	require.NotEqual(t, tokenA, tokenB)
+}
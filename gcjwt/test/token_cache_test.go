@@ -0,0 +1,145 @@
+package gcjwt_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bionicotaku/lingo-utils/gcjwt"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"golang.org/x/oauth2"
+)
+
+// countingFactory 每次调用签发一个带序号的 Token。
+func countingFactory(calls *atomic.Int32, ttl time.Duration, delay time.Duration) gcjwt.TokenSourceFactory {
+	return func(context.Context, string) (oauth2.TokenSource, error) {
+		n := calls.Add(1)
+		time.Sleep(delay)
+		return oauth2.StaticTokenSource(&oauth2.Token{
+			AccessToken: fmt.Sprintf("token-%d", n),
+			Expiry:      time.Now().Add(ttl),
+		}), nil
+	}
+}
+
+func TestTokenCacheSingleflight(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	cache := gcjwt.NewTokenCache(gcjwt.WithCacheFactory(countingFactory(&calls, time.Hour, 50*time.Millisecond)))
+	t.Cleanup(cache.Close)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := cache.Token(context.Background(), "https://service")
+			require.NoError(t, err)
+			require.Equal(t, "token-1", token)
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, calls.Load())
+
+	_, err := cache.Token(context.Background(), "https://other")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, calls.Load(), "audiences are cached independently")
+}
+
+func TestTokenCacheRetriesInitFailure(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	cache := gcjwt.NewTokenCache(gcjwt.WithCacheFactory(func(context.Context, string) (oauth2.TokenSource, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("metadata unreachable")
+		}
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "recovered", Expiry: time.Now().Add(time.Hour)}), nil
+	}))
+	t.Cleanup(cache.Close)
+
+	_, err := cache.Token(context.Background(), "https://service")
+	require.ErrorIs(t, err, gcjwt.ErrTokenSourceInit)
+
+	token, err := cache.Token(context.Background(), "https://service")
+	require.NoError(t, err)
+	require.Equal(t, "recovered", token)
+}
+
+func TestTokenCacheProactiveRefresh(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+
+	var calls atomic.Int32
+	cache := gcjwt.NewTokenCache(
+		gcjwt.WithCacheFactory(countingFactory(&calls, 12*time.Second, 0)),
+		gcjwt.WithRefreshBefore(11*time.Second),
+		gcjwt.WithCacheMeter(meter),
+	)
+	t.Cleanup(cache.Close)
+
+	token, err := cache.Token(context.Background(), "https://service")
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token)
+
+	require.Eventually(t, func() bool { return calls.Load() >= 2 }, 3*time.Second, 50*time.Millisecond)
+	token, err = cache.Token(context.Background(), "https://service")
+	require.NoError(t, err)
+	require.NotEqual(t, "token-1", token, "request path should see the refreshed token")
+
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &data))
+	names := map[string]bool{}
+	for _, sm := range data.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names[m.Name] = true
+		}
+	}
+	require.True(t, names["gcjwt_token_fetch_duration_ms"])
+	require.True(t, names["gcjwt_token_time_to_expiry_seconds"])
+}
+
+func TestTokenCacheCloseUnregistersGauge(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+
+	var calls atomic.Int32
+	cache := gcjwt.NewTokenCache(
+		gcjwt.WithCacheFactory(countingFactory(&calls, time.Hour, 0)),
+		gcjwt.WithCacheMeter(meter),
+	)
+	expiryPoints := func() int {
+		var data metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &data))
+		n := 0
+		for _, sm := range data.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if gauge, ok := m.Data.(metricdata.Gauge[float64]); ok && m.Name == "gcjwt_token_time_to_expiry_seconds" {
+					n += len(gauge.DataPoints)
+				}
+			}
+		}
+		return n
+	}
+
+	_, err := cache.Token(context.Background(), "https://service")
+	require.NoError(t, err)
+	require.Equal(t, 1, expiryPoints())
+
+	cache.Close()
+	// 关闭后仍可同步获取，但回调已注销，不再上报该缓存的 Token。
+	_, err = cache.Token(context.Background(), "https://service")
+	require.NoError(t, err)
+	require.Zero(t, expiryPoints())
+}
@@ -0,0 +1,120 @@
+package gcjwt_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bionicotaku/lingo-utils/gcjwt"
+	"github.com/bionicotaku/lingo-utils/gcjwt/testissuer"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const transportAudience = "https://service-b.run.app/"
+
+func newTransportIssuer(t *testing.T) *testissuer.Issuer {
+	t.Helper()
+	iss, err := testissuer.New()
+	require.NoError(t, err)
+	return iss
+}
+
+func serverOpts(iss *testissuer.Issuer) []gcjwt.ServerOption {
+	return []gcjwt.ServerOption{
+		gcjwt.WithExpectedAudience(transportAudience),
+		gcjwt.WithServerLogger(log.NewStdLogger(io.Discard)),
+		gcjwt.WithSignatureVerification(true),
+		gcjwt.WithKeySource(iss.KeySource()),
+	}
+}
+
+func TestHTTPTransportAndHandler(t *testing.T) {
+	t.Parallel()
+
+	iss := newTransportIssuer(t)
+	handler := gcjwt.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := gcjwt.FromContext(r.Context())
+		require.True(t, ok)
+		_, _ = io.WriteString(w, claims.Email)
+	}), serverOpts(iss)...)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: gcjwt.Transport(nil,
+		gcjwt.WithAudience(transportAudience),
+		gcjwt.WithTokenCache(iss.TokenCache("svc-a@example.com")),
+	)}
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/webhook", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "svc-a@example.com", string(body))
+	require.Empty(t, req.Header.Get("authorization"), "original request must not be mutated")
+
+	anon, err := http.Get(srv.URL + "/webhook")
+	require.NoError(t, err)
+	defer anon.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, anon.StatusCode)
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	t.Parallel()
+
+	iss := newTransportIssuer(t)
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(gcjwt.UnaryServerInterceptor(serverOpts(iss)...)),
+		grpc.StreamInterceptor(gcjwt.StreamServerInterceptor(serverOpts(iss)...)),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	dial := func(opts ...grpc.DialOption) healthpb.HealthClient {
+		opts = append(opts,
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		return healthpb.NewHealthClient(conn)
+	}
+
+	clientOpts := []gcjwt.ClientOption{
+		gcjwt.WithAudience(transportAudience),
+		gcjwt.WithTokenCache(iss.TokenCache("svc-a@example.com")),
+	}
+	authed := dial(
+		grpc.WithUnaryInterceptor(gcjwt.UnaryClientInterceptor(clientOpts...)),
+		grpc.WithStreamInterceptor(gcjwt.StreamClientInterceptor(clientOpts...)),
+	)
+
+	ctx := context.Background()
+	resp, err := authed.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	stream, err := authed.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	_, err = dial().Check(ctx, &healthpb.HealthCheckRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
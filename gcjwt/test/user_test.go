@@ -0,0 +1,189 @@
+package gcjwt_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bionicotaku/lingo-utils/gcjwt"
+	"github.com/bionicotaku/lingo-utils/gcjwt/testissuer"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/require"
+)
+
+// newOIDCProvider 启动带 discovery 与 JWKS 的模拟身份提供方，issuer 即服务地址。
+func newOIDCProvider(t *testing.T) (*testissuer.Issuer, string) {
+	t.Helper()
+	var iss *testissuer.Issuer
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   srv.URL,
+			"jwks_uri": srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(iss.JWKS())
+	})
+
+	var err error
+	iss, err = testissuer.New(testissuer.WithIssuer(srv.URL))
+	require.NoError(t, err)
+	return iss, srv.URL
+}
+
+func TestOIDCVerifierDiscovery(t *testing.T) {
+	t.Parallel()
+
+	iss, issuerURL := newOIDCProvider(t)
+	verifier, err := gcjwt.NewOIDCVerifier(gcjwt.UserConfig{
+		Issuer:     issuerURL,
+		Audience:   "lingo-app",
+		RolesClaim: "app_metadata.roles",
+	})
+	require.NoError(t, err)
+	t.Cleanup(verifier.Close)
+
+	token, err := iss.MintClaims(testissuer.Claims{
+		Email:    "alice@example.com",
+		Audience: "lingo-app",
+		Subject:  "user-1",
+		Extra: map[string]interface{}{
+			"app_metadata": map[string]interface{}{"roles": []string{"admin", "editor"}},
+			"scope":        "read:orders write:orders",
+		},
+	})
+	require.NoError(t, err)
+
+	claims, err := verifier.Verify(context.Background(), token)
+	require.NoError(t, err)
+	require.Equal(t, "user-1", claims.Subject)
+	require.Equal(t, []string{"lingo-app"}, claims.Audience)
+	require.True(t, claims.EmailVerified)
+	require.True(t, claims.HasRole("admin"))
+	require.True(t, claims.HasScope("write:orders"))
+	require.False(t, claims.HasScope("admin"))
+
+	arrayAud, err := iss.MintClaims(testissuer.Claims{
+		Audience: "ignored",
+		Extra:    map[string]interface{}{"aud": []string{"other", "lingo-app"}},
+	})
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), arrayAud)
+	require.NoError(t, err)
+
+	wrongAud, err := iss.Mint("alice@example.com", "other-app")
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), wrongAud)
+	require.ErrorIs(t, err, gcjwt.ErrInvalidAudience)
+
+	stranger, err := testissuer.New(testissuer.WithIssuer(issuerURL))
+	require.NoError(t, err)
+	forged, err := stranger.Mint("alice@example.com", "lingo-app")
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), forged)
+	require.ErrorIs(t, err, gcjwt.ErrInvalidSignature)
+}
+
+func TestOIDCVerifierDiscoveryOutsideLock(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	verifier, err := gcjwt.NewOIDCVerifier(gcjwt.UserConfig{Issuer: srv.URL, Audience: "lingo-app"})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			_, err := verifier.Verify(ctx, "header.payload.signature")
+			require.ErrorIs(t, err, gcjwt.ErrInvalidSignature)
+		}()
+	}
+	require.Eventually(t, func() bool { return hits.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	// discovery 阻塞期间 Close 不应等待它完成，调用方按自身上下文超时返回。
+	closed := make(chan struct{})
+	go func() {
+		verifier.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked by in-flight discovery")
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, hits.Load(), "concurrent discovery must be merged")
+}
+
+func TestUserMiddlewareCoexistsWithServiceToken(t *testing.T) {
+	t.Parallel()
+
+	users, err := testissuer.New(testissuer.WithIssuer("https://securetoken.google.com/lingo"))
+	require.NoError(t, err)
+	services, err := testissuer.New()
+	require.NoError(t, err)
+
+	verifier, err := gcjwt.NewOIDCVerifier(gcjwt.UserConfig{
+		Issuer:   "https://securetoken.google.com/lingo",
+		Audience: "lingo",
+		Required: true,
+	}, gcjwt.WithOIDCKeySource(users.KeySource()))
+	require.NoError(t, err)
+
+	logger := log.NewStdLogger(io.Discard)
+	server := gcjwt.Server(
+		gcjwt.WithExpectedAudience("https://service"),
+		gcjwt.WithServerLogger(logger),
+		gcjwt.WithSignatureVerification(true),
+		gcjwt.WithKeySource(services.KeySource()),
+	)
+	handler := server(gcjwt.User(verifier)(func(ctx context.Context, _ interface{}) (interface{}, error) {
+		svc, ok := gcjwt.FromContext(ctx)
+		require.True(t, ok)
+		require.Equal(t, "gateway@project.iam.gserviceaccount.com", svc.Email)
+		user, ok := gcjwt.UserFromContext(ctx)
+		require.True(t, ok)
+		require.Equal(t, "bob@example.com", user.Email)
+		return "ok", nil
+	}))
+
+	serviceToken, err := services.Mint("gateway@project.iam.gserviceaccount.com", "https://service")
+	require.NoError(t, err)
+	userToken, err := users.MintClaims(testissuer.Claims{Email: "bob@example.com", Audience: "lingo", TTL: 5 * time.Minute})
+	require.NoError(t, err)
+
+	header := newMockHeader()
+	header.Set("authorization", "Bearer "+serviceToken)
+	header.Set(gcjwt.DefaultUserHeaderKey, "Bearer "+userToken)
+	_, err = handler(transport.NewServerContext(context.Background(), &mockServerTransport{header: header}), "req")
+	require.NoError(t, err)
+
+	header = newMockHeader()
+	header.Set("authorization", "Bearer "+serviceToken)
+	_, err = handler(transport.NewServerContext(context.Background(), &mockServerTransport{header: header}), "req")
+	require.ErrorIs(t, err, gcjwt.ErrMissingUserToken)
+}
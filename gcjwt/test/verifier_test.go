@@ -0,0 +1,227 @@
+package gcjwt_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bionicotaku/lingo-utils/gcjwt"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/require"
+)
+
+const testKid = "test-key"
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	headerBytes, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	require.NoError(t, err)
+	payloadBytes, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signing := base64.RawURLEncoding.EncodeToString(headerBytes) + "." + base64.RawURLEncoding.EncodeToString(payloadBytes)
+	digest := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func googleClaims(aud string, exp time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   "https://accounts.google.com",
+		"aud":   aud,
+		"email": "svc@example.com",
+		"exp":   exp.Unix(),
+	}
+}
+
+func jwksJSON(t *testing.T, kid string, pub *rsa.PublicKey) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func callServer(token string, opts ...gcjwt.ServerOption) error {
+	header := newMockHeader()
+	header.Set("authorization", "Bearer "+token)
+	ctx := transport.NewServerContext(context.Background(), &mockServerTransport{header: header})
+	opts = append(opts, gcjwt.WithServerLogger(log.NewStdLogger(io.Discard)))
+	_, err := gcjwt.Server(opts...)(dummyNext)(ctx, "req")
+	return err
+}
+
+func TestServerSignatureVerification(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keys := gcjwt.StaticKeySource{testKid: &key.PublicKey}
+	aud := "https://service"
+	opts := []gcjwt.ServerOption{
+		gcjwt.WithExpectedAudience(aud),
+		gcjwt.WithSignatureVerification(true),
+		gcjwt.WithKeySource(keys),
+	}
+
+	valid := signToken(t, key, testKid, googleClaims(aud, time.Now().Add(time.Hour)))
+	require.NoError(t, callServer(valid, opts...))
+
+	// 篡改 payload 后签名不再匹配。
+	forged := makeToken(t, aud, "attacker@example.com", time.Now().Add(time.Hour))
+	parts := strings.Split(valid, ".")
+	parts[1] = strings.Split(forged, ".")[1]
+	require.ErrorIs(t, callServer(strings.Join(parts, "."), opts...), gcjwt.ErrInvalidSignature)
+
+	// 未签名的 Token 与未知 kid 均被拒绝。
+	require.ErrorIs(t, callServer(forged, opts...), gcjwt.ErrInvalidSignature)
+	require.ErrorIs(t, callServer(signToken(t, key, "other", googleClaims(aud, time.Now().Add(time.Hour))), opts...), gcjwt.ErrInvalidSignature)
+
+	// 非 Google issuer 被拒绝，显式允许后通过。
+	claims := googleClaims(aud, time.Now().Add(time.Hour))
+	claims["iss"] = "https://evil.example.com"
+	foreign := signToken(t, key, testKid, claims)
+	require.ErrorIs(t, callServer(foreign, opts...), gcjwt.ErrInvalidIssuer)
+	require.NoError(t, callServer(foreign, append(opts, gcjwt.WithAllowedIssuers("https://evil.example.com"))...))
+}
+
+func TestServerClockSkew(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	aud := "https://service"
+	token := signToken(t, key, testKid, googleClaims(aud, time.Now().Add(-10*time.Second)))
+	opts := []gcjwt.ServerOption{
+		gcjwt.WithExpectedAudience(aud),
+		gcjwt.WithSignatureVerification(true),
+		gcjwt.WithKeySource(gcjwt.StaticKeySource{testKid: &key.PublicKey}),
+	}
+
+	require.ErrorIs(t, callServer(token, opts...), gcjwt.ErrTokenExpired)
+	require.NoError(t, callServer(token, append(opts, gcjwt.WithClockSkew(time.Minute))...))
+}
+
+func TestJWKSKeySource(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	body := jwksJSON(t, testKid, &key.PublicKey)
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	source := gcjwt.NewJWKSKeySource(srv.URL)
+	t.Cleanup(source.Close)
+
+	ctx := context.Background()
+	pub, err := source.Key(ctx, testKid)
+	require.NoError(t, err)
+	require.Equal(t, 0, pub.N.Cmp(key.PublicKey.N))
+
+	_, err = source.Key(ctx, testKid)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, hits.Load(), "cached kid must not refetch")
+
+	_, err = source.Key(ctx, "unknown")
+	require.ErrorIs(t, err, gcjwt.ErrKeyNotFound)
+	require.EqualValues(t, 1, hits.Load(), "unknown kid refetch is rate limited")
+}
+
+func TestJWKSKeySourceRefreshDetachedFromCaller(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	body := jwksJSON(t, testKid, &key.PublicKey)
+
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		<-release
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	source := gcjwt.NewJWKSKeySource(srv.URL)
+	t.Cleanup(source.Close)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := source.Key(firstCtx, testKid)
+		firstErr <- err
+	}()
+	require.Eventually(t, func() bool { return hits.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	secondErr := make(chan error, 1)
+	go func() {
+		_, err := source.Key(context.Background(), testKid)
+		secondErr <- err
+	}()
+
+	// 首个调用方取消只影响自己，共享的拉取继续完成。
+	cancelFirst()
+	require.ErrorIs(t, <-firstErr, context.Canceled)
+	close(release)
+	require.NoError(t, <-secondErr)
+	require.EqualValues(t, 1, hits.Load())
+}
+
+func TestLoadJWKSFile(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksJSON(t, testKid, &key.PublicKey), 0o600))
+
+	comp, cleanup, err := gcjwt.NewComponent(gcjwt.Config{
+		Server: &gcjwt.ServerConfig{
+			ExpectedAudience: "https://service",
+			Required:         true,
+			VerifySignature:  true,
+			JWKSFile:         path,
+		},
+	}, log.NewStdLogger(io.Discard))
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	mw, err := gcjwt.ProvideServerMiddleware(comp)
+	require.NoError(t, err)
+
+	header := newMockHeader()
+	header.Set("authorization", "Bearer "+signToken(t, key, testKid, googleClaims("https://service", time.Now().Add(time.Hour))))
+	ctx := transport.NewServerContext(context.Background(), &mockServerTransport{header: header})
+	_, err = mw(dummyNext)(ctx, "req")
+	require.NoError(t, err)
+}
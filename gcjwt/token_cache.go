@@ -0,0 +1,326 @@
+package gcjwt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultRefreshBefore   = 5 * time.Minute
+	defaultTokenFetchLimit = 10 * time.Second
+	refreshRetryInterval   = 30 * time.Second
+	// tokenExpiryDelta 与 oauth2 一致，过期前 10 秒即视为不可用。
+	tokenExpiryDelta = 10 * time.Second
+)
+
+// TokenCacheOption 自定义 TokenCache。
+type TokenCacheOption func(*TokenCache)
+
+// WithCacheFactory 指定缓存使用的 TokenSource 工厂；未指定时使用全局工厂。
+func WithCacheFactory(factory TokenSourceFactory) TokenCacheOption {
+	return func(c *TokenCache) {
+		c.factory = factory
+	}
+}
+
+// WithRefreshBefore 指定在 exp 之前多久后台刷新，默认 5 分钟。
+func WithRefreshBefore(d time.Duration) TokenCacheOption {
+	return func(c *TokenCache) {
+		if d > 0 {
+			c.refreshBefore = d
+		}
+	}
+}
+
+// WithCacheLogger 注入日志器。
+func WithCacheLogger(logger log.Logger) TokenCacheOption {
+	return func(c *TokenCache) {
+		if logger != nil {
+			c.logger = log.NewHelper(log.With(logger, "logger", "gcjwt.token_cache"))
+		}
+	}
+}
+
+// WithCacheMeter 指定记录指标的 Meter，默认使用全局 MeterProvider。
+func WithCacheMeter(meter metric.Meter) TokenCacheOption {
+	return func(c *TokenCache) {
+		c.meter = meter
+	}
+}
+
+// TokenCache 按 audience 缓存 ID Token。
+//
+// 特性：
+//   - 并发未命中通过 singleflight 合并为一次获取
+//   - 在 exp 前 refreshBefore 后台刷新，请求路径不承担 Metadata Server 往返
+//   - 每次获取都重新调用工厂，初始化失败不会被永久缓存
+//   - 指标：gcjwt_token_fetch_duration_ms、gcjwt_token_fetch_failures_total、gcjwt_token_time_to_expiry_seconds
+type TokenCache struct {
+	factory       TokenSourceFactory
+	refreshBefore time.Duration
+	logger        *log.Helper
+	meter         metric.Meter
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	closed  bool
+	group   singleflight.Group
+
+	fetchLatency metric.Float64Histogram
+	fetchFailure metric.Int64Counter
+	expiryGauge  metric.Registration
+}
+
+type cacheEntry struct {
+	token *oauth2.Token
+	timer *time.Timer
+}
+
+var (
+	defaultCacheOnce sync.Once
+	defaultCache     *TokenCache
+)
+
+// DefaultTokenCache 返回进程级缓存，未指定工厂的 TokenSource 均共享它。
+func DefaultTokenCache() *TokenCache {
+	defaultCacheOnce.Do(func() {
+		defaultCache = NewTokenCache()
+	})
+	return defaultCache
+}
+
+// NewTokenCache 创建独立的 Token 缓存。
+func NewTokenCache(opts ...TokenCacheOption) *TokenCache {
+	c := &TokenCache{
+		refreshBefore: defaultRefreshBefore,
+		entries:       make(map[string]*cacheEntry),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.logger == nil {
+		c.logger = log.NewHelper(log.With(log.GetLogger(), "logger", "gcjwt.token_cache"))
+	}
+	if c.meter == nil {
+		c.meter = otel.GetMeterProvider().Meter("lingo-utils/gcjwt")
+	}
+	c.registerMetrics()
+	return c
+}
+
+func (c *TokenCache) registerMetrics() {
+	var err error
+	if c.fetchLatency, err = c.meter.Float64Histogram("gcjwt_token_fetch_duration_ms", metric.WithUnit("ms")); err != nil {
+		c.logger.Warnw("msg", "gcjwt: register token_fetch_duration", "err", err)
+	}
+	if c.fetchFailure, err = c.meter.Int64Counter("gcjwt_token_fetch_failures_total"); err != nil {
+		c.logger.Warnw("msg", "gcjwt: register token_fetch_failures", "err", err)
+	}
+	expiry, err := c.meter.Float64ObservableGauge("gcjwt_token_time_to_expiry_seconds", metric.WithUnit("s"))
+	if err != nil {
+		c.logger.Warnw("msg", "gcjwt: register token_time_to_expiry", "err", err)
+		return
+	}
+	// 保留回调注册，Close 时注销，避免 MeterProvider 持有已关闭缓存。
+	c.expiryGauge, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for aud, e := range c.entries {
+			if e.token == nil || e.token.Expiry.IsZero() {
+				continue
+			}
+			o.ObserveFloat64(expiry, time.Until(e.token.Expiry).Seconds(), metric.WithAttributes(attribute.String("gcjwt.audience", aud)))
+		}
+		return nil
+	}, expiry)
+	if err != nil {
+		c.logger.Warnw("msg", "gcjwt: register token_time_to_expiry callback", "err", err)
+	}
+}
+
+// Token 返回 audience 对应的可用 ID Token，缓存缺失或即将过期时同步获取。
+func (c *TokenCache) Token(ctx context.Context, audience string) (string, error) {
+	c.mu.Lock()
+	if e := c.entries[audience]; e != nil && tokenUsable(e.token) {
+		token := e.token.AccessToken
+		c.mu.Unlock()
+		return token, nil
+	}
+	c.mu.Unlock()
+
+	ch := c.group.DoChan(audience, func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultTokenFetchLimit)
+		defer cancel()
+		return c.fetch(fetchCtx, audience, "request")
+	})
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("%w: %v", ErrTokenAcquire, ctx.Err())
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(*oauth2.Token).AccessToken, nil
+	}
+}
+
+// Invalidate 丢弃所有缓存并停止后台刷新。
+func (c *TokenCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for aud, e := range c.entries {
+		if e.timer != nil {
+			e.timer.Stop()
+		}
+		delete(c.entries, aud)
+	}
+}
+
+// Close 停止后台刷新并注销指标回调；之后的 Token 调用仍可同步获取，但不再调度刷新。
+func (c *TokenCache) Close() {
+	c.Invalidate()
+	c.mu.Lock()
+	c.closed = true
+	reg := c.expiryGauge
+	c.expiryGauge = nil
+	c.mu.Unlock()
+	if reg != nil {
+		if err := reg.Unregister(); err != nil {
+			c.logger.Warnw("msg", "gcjwt: unregister token_time_to_expiry callback", "err", err)
+		}
+	}
+}
+
+func (c *TokenCache) fetch(ctx context.Context, audience, trigger string) (*oauth2.Token, error) {
+	factory := c.factory
+	if factory == nil {
+		factoryMu.RLock()
+		factory = idTokenSourceFactory
+		factoryMu.RUnlock()
+	}
+
+	start := time.Now()
+	token, stage, err := fetchToken(ctx, factory, audience)
+	latency := time.Since(start)
+	attrs := []attribute.KeyValue{
+		attribute.String("gcjwt.audience", audience),
+		attribute.String("gcjwt.trigger", trigger),
+	}
+	if err != nil {
+		if c.fetchFailure != nil {
+			c.fetchFailure.Add(ctx, 1, metric.WithAttributes(append(attrs, attribute.String("gcjwt.stage", stage))...))
+		}
+		if c.fetchLatency != nil {
+			c.fetchLatency.Record(ctx, float64(latency.Milliseconds()), metric.WithAttributes(append(attrs, attribute.String("gcjwt.result", "error"))...))
+		}
+		return nil, err
+	}
+	if c.fetchLatency != nil {
+		c.fetchLatency.Record(ctx, float64(latency.Milliseconds()), metric.WithAttributes(append(attrs, attribute.String("gcjwt.result", "success"))...))
+	}
+
+	c.store(audience, token)
+	return token, nil
+}
+
+// fetchToken 每次都通过工厂创建 TokenSource，使初始化失败可以在下次重试。
+func fetchToken(ctx context.Context, factory TokenSourceFactory, audience string) (*oauth2.Token, string, error) {
+	ts, err := factory(ctx, audience)
+	if err != nil {
+		return nil, "init", fmt.Errorf("%w: %v", ErrTokenSourceInit, err)
+	}
+	token, err := ts.Token()
+	if err != nil {
+		return nil, "acquire", fmt.Errorf("%w: %v", ErrTokenAcquire, err)
+	}
+	cached := *token
+	if cached.Expiry.IsZero() {
+		cached.Expiry = jwtExpiry(cached.AccessToken)
+	}
+	return &cached, "", nil
+}
+
+func (c *TokenCache) store(audience string, token *oauth2.Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entries[audience]
+	if e == nil {
+		e = &cacheEntry{}
+		c.entries[audience] = e
+	}
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	e.token = token
+	if c.closed || token.Expiry.IsZero() || !tokenUsable(token) {
+		return
+	}
+	delay := time.Until(token.Expiry) - c.refreshBefore
+	if delay < 0 {
+		delay = time.Until(token.Expiry) / 2
+	}
+	if delay < time.Second {
+		delay = time.Second
+	}
+	e.timer = time.AfterFunc(delay, func() { c.refresh(audience) })
+}
+
+// refresh 在后台提前换新 Token；失败时在旧 Token 仍有效期间按固定间隔重试。
+func (c *TokenCache) refresh(audience string) {
+	_, err, _ := c.group.Do(audience, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultTokenFetchLimit)
+		defer cancel()
+		return c.fetch(ctx, audience, "refresh")
+	})
+	if err == nil {
+		return
+	}
+	c.logger.Warnf("background token refresh failed (audience=%s): %v", audience, err)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entries[audience]
+	if c.closed || e == nil || !tokenUsable(e.token) {
+		return
+	}
+	e.timer = time.AfterFunc(refreshRetryInterval, func() { c.refresh(audience) })
+}
+
+func tokenUsable(token *oauth2.Token) bool {
+	if token == nil || token.AccessToken == "" {
+		return false
+	}
+	return token.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(token.Expiry)
+}
+
+// jwtExpiry 在 oauth2.Token 未携带 Expiry 时从 JWT payload 读取 exp。
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		ExpiresAt int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ExpiresAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.ExpiresAt, 0)
+}
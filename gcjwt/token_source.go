@@ -2,7 +2,6 @@ package gcjwt
 
 import (
 	"context"
-	"fmt"
 	"sync"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -19,8 +18,12 @@ func defaultIDTokenSourceFactory(ctx context.Context, audience string) (oauth2.T
 	return idtoken.NewTokenSource(ctx, audience)
 }
 
-// SetTokenSourceFactory 仅用于测试覆盖场景，允许注入自定义工厂。
+// TokenSourceFactory 按 audience 创建底层 oauth2.TokenSource。
+type TokenSourceFactory func(ctx context.Context, audience string) (oauth2.TokenSource, error)
+
+// SetTokenSourceFactory 仅用于测试覆盖场景，允许注入自定义工厂；同时清空进程级缓存。
 func SetTokenSourceFactory(factory func(context.Context, string) (oauth2.TokenSource, error)) {
+	DefaultTokenCache().Invalidate()
 	factoryMu.Lock()
 	defer factoryMu.Unlock()
 	if factory == nil {
@@ -33,9 +36,10 @@ func SetTokenSourceFactory(factory func(context.Context, string) (oauth2.TokenSo
 // TokenSource 封装 Cloud Run ID Token 的获取逻辑。
 //
 // 特性：
-//   - 延迟初始化：首次调用 Token 时才初始化底层 oauth2.TokenSource
+//   - 按 audience 共享进程级 TokenCache，多个中间件实例不会重复获取
 //   - 线程安全：Token 方法可被多个 goroutine 并发调用
-//   - 自动缓存与刷新：依赖 oauth2.TokenSource 的内置缓存机制（约 1 小时）
+//   - 后台提前刷新：请求路径通常直接命中缓存
+//   - 初始化失败不会被永久缓存，下次调用会重试
 //
 // 使用示例：
 //
@@ -47,69 +51,36 @@ func SetTokenSourceFactory(factory func(context.Context, string) (oauth2.TokenSo
 type TokenSource struct {
 	audience string
 	logger   *log.Helper
-
-	once    sync.Once
-	ts      oauth2.TokenSource
-	initErr error
+	cache    *TokenCache
 }
 
 // NewTokenSource 创建新的 TokenSource 实例。
 //
 // 参数：
 //   - audience: 目标服务的 URL（例如 https://service-b.run.app/）
-//   - logger: Kratos 日志器，用于记录初始化和获取过程
+//   - logger: Kratos 日志器，用于记录获取过程
 //
 // 注意：
-//   - TokenSource 会在首次调用 Token() 时延迟初始化
-//   - audience 不能为空，否则初始化会失败
+//   - 默认使用 DefaultTokenCache，可通过 WithTokenCache 在客户端中间件中替换
+//   - audience 不能为空，否则获取会失败
 func NewTokenSource(audience string, logger log.Logger) *TokenSource {
 	return &TokenSource{
 		audience: audience,
-		logger:   log.NewHelper(log.With(logger, "component", "gcjwt.token")),
+		logger:   log.NewHelper(log.With(logger, "component", "gcjwt.token", "logger", "gcjwt.token")),
+		cache:    DefaultTokenCache(),
 	}
 }
 
 // Token 返回当前可用的 ID Token 字符串。
 //
-// 行为：
-//   - 首次调用：初始化 oauth2.TokenSource（连接 Metadata Server）
-//   - 后续调用：返回缓存的 Token，过期时自动刷新
-//
-// 线程安全：
-//   - 可被多个 goroutine 并发调用
-//   - 使用 sync.Once 保证初始化只执行一次
-//
 // 返回：
 //   - token: JWT 格式的 ID Token 字符串
 //   - error: 初始化失败或获取失败时返回错误（ErrTokenSourceInit 或 ErrTokenAcquire）
-//
-// 性能：
-//   - Token 有效期约 1 小时，缓存避免频繁调用 Metadata Server
-//   - 自动刷新发生在 Token 过期前
 func (s *TokenSource) Token(ctx context.Context) (string, error) {
-	s.once.Do(func() {
-		s.logger.Infof("initializing idtoken source (audience=%s)", s.audience)
-		factoryMu.RLock()
-		factory := idTokenSourceFactory
-		factoryMu.RUnlock()
-
-		s.ts, s.initErr = factory(ctx, s.audience)
-		if s.initErr != nil {
-			s.logger.Errorf("token source init failed: %v", s.initErr)
-			return
-		}
-		s.logger.Info("token source initialized successfully")
-	})
-
-	if s.initErr != nil {
-		return "", fmt.Errorf("%w: %v", ErrTokenSourceInit, s.initErr)
-	}
-
-	token, err := s.ts.Token()
+	token, err := s.cache.Token(ctx, s.audience)
 	if err != nil {
-		s.logger.Errorf("failed to acquire id token: %v", err)
-		return "", fmt.Errorf("%w: %v", ErrTokenAcquire, err)
+		s.logger.Errorf("failed to acquire id token (audience=%s): %v", s.audience, err)
+		return "", err
 	}
-
-	return token.AccessToken, nil
+	return token, nil
 }
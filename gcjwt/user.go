@@ -0,0 +1,405 @@
+package gcjwt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"golang.org/x/sync/singleflight"
+)
+
+var errVerifierClosed = errors.New("oidc verifier closed")
+
+const (
+	// DefaultUserHeaderKey 是终端用户 Token 默认所在的 Header，与服务间 authorization 并存。
+	DefaultUserHeaderKey = "x-user-authorization"
+
+	defaultRolesClaim  = "roles"
+	defaultScopesClaim = "scope"
+)
+
+// UserConfig 定义终端用户（Firebase/Supabase/通用 OIDC）Token 的校验配置。
+type UserConfig struct {
+	// Issuer 为 OIDC issuer URL，用于 discovery 与 iss 校验。
+	Issuer   string `json:"issuer" yaml:"issuer"`
+	Audience string `json:"audience" yaml:"audience"`
+	// JWKSURL 非空时跳过 discovery 直接使用。
+	JWKSURL   string        `json:"jwks_url,omitempty" yaml:"jwks_url,omitempty"`
+	HeaderKey string        `json:"header_key,omitempty" yaml:"header_key,omitempty"`
+	Required  bool          `json:"required" yaml:"required"`
+	ClockSkew time.Duration `json:"clock_skew,omitempty" yaml:"clock_skew,omitempty"`
+	// RolesClaim / ScopesClaim 为点分路径（如 app_metadata.roles），
+	// 取值可为字符串数组或空格分隔字符串，默认 roles / scope。
+	RolesClaim  string `json:"roles_claim,omitempty" yaml:"roles_claim,omitempty"`
+	ScopesClaim string `json:"scopes_claim,omitempty" yaml:"scopes_claim,omitempty"`
+}
+
+// Validate 校验终端用户配置。
+func (c *UserConfig) Validate() error {
+	if c.Issuer == "" {
+		return fmt.Errorf("user issuer is required")
+	}
+	if c.Audience == "" {
+		return fmt.Errorf("user audience is required")
+	}
+	if c.ClockSkew < 0 {
+		return fmt.Errorf("clock_skew must not be negative")
+	}
+	return nil
+}
+
+// UserClaims 表示终端用户 ID Token 中应用层关注的字段。
+type UserClaims struct {
+	Subject       string
+	Issuer        string
+	Audience      []string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Roles         []string
+	Scopes        []string
+	IssuedAt      int64
+	ExpiresAt     int64
+	// Raw 保留完整 payload，供业务读取自定义 claim。
+	Raw map[string]interface{}
+}
+
+// HasRole 判断用户是否具有指定角色。
+func (c *UserClaims) HasRole(role string) bool {
+	return containsString(c.Roles, role)
+}
+
+// HasScope 判断用户是否具有指定 scope。
+func (c *UserClaims) HasScope(scope string) bool {
+	return containsString(c.Scopes, scope)
+}
+
+// String 返回可读表示，用于日志。
+func (c *UserClaims) String() string {
+	return fmt.Sprintf("UserClaims{sub=%s, iss=%s, roles=%v, exp=%s}",
+		c.Subject, c.Issuer, c.Roles, time.Unix(c.ExpiresAt, 0).UTC().Format(time.RFC3339))
+}
+
+type userContextKey struct{}
+
+// NewUserContext 将 UserClaims 注入上下文。
+func NewUserContext(ctx context.Context, claims *UserClaims) context.Context {
+	return context.WithValue(ctx, userContextKey{}, claims)
+}
+
+// UserFromContext 从上下文中提取 UserClaims。
+func UserFromContext(ctx context.Context) (*UserClaims, bool) {
+	claims, ok := ctx.Value(userContextKey{}).(*UserClaims)
+	return claims, ok
+}
+
+// OIDCOption 自定义 OIDCVerifier。
+type OIDCOption func(*OIDCVerifier)
+
+// WithOIDCHTTPClient 指定 discovery 与 JWKS 使用的 HTTP 客户端。
+func WithOIDCHTTPClient(client *http.Client) OIDCOption {
+	return func(v *OIDCVerifier) {
+		if client != nil {
+			v.client = client
+		}
+	}
+}
+
+// WithOIDCKeySource 直接指定公钥来源，跳过 discovery。
+func WithOIDCKeySource(keys KeySource) OIDCOption {
+	return func(v *OIDCVerifier) {
+		v.keys = keys
+	}
+}
+
+// WithOIDCLogger 注入日志器。
+func WithOIDCLogger(logger log.Logger) OIDCOption {
+	return func(v *OIDCVerifier) {
+		if logger != nil {
+			v.logger = logger
+		}
+	}
+}
+
+// OIDCVerifier 校验终端用户 ID Token：RS256 验签、iss、aud、exp，并映射角色与 scope。
+//
+// 公钥来源按以下顺序确定：WithOIDCKeySource → UserConfig.JWKSURL →
+// `<issuer>/.well-known/openid-configuration` 中的 jwks_uri（首次校验时拉取，失败会在下次重试）。
+type OIDCVerifier struct {
+	cfg    UserConfig
+	client *http.Client
+	logger log.Logger
+	helper *log.Helper
+
+	mu     sync.Mutex
+	keys   KeySource
+	jwks   *JWKSKeySource
+	closed bool
+
+	discovery singleflight.Group
+}
+
+// NewOIDCVerifier 创建终端用户 Token 校验器。
+func NewOIDCVerifier(cfg UserConfig, opts ...OIDCOption) (*OIDCVerifier, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.HeaderKey == "" {
+		cfg.HeaderKey = DefaultUserHeaderKey
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = defaultRolesClaim
+	}
+	if cfg.ScopesClaim == "" {
+		cfg.ScopesClaim = defaultScopesClaim
+	}
+	v := &OIDCVerifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: defaultJWKSFetchTimeout},
+		logger: log.NewStdLogger(io.Discard),
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	v.helper = log.NewHelper(log.With(v.logger, "logger", "gcjwt.oidc"))
+	if v.keys == nil && cfg.JWKSURL != "" {
+		v.jwks = NewJWKSKeySource(cfg.JWKSURL, WithJWKSHTTPClient(v.client), WithJWKSLogger(v.logger))
+		v.keys = v.jwks
+	}
+	return v, nil
+}
+
+// Verify 校验 Token 并返回 UserClaims；失败时返回 gcjwt 定义的 Kratos 错误。
+func (v *OIDCVerifier) Verify(ctx context.Context, token string) (*UserClaims, error) {
+	keys, err := v.keySource(ctx)
+	if err != nil {
+		v.helper.Errorf("resolve oidc key source: %v", err)
+		return nil, ErrInvalidSignature
+	}
+	if err := verifyTokenSignature(ctx, token, keys); err != nil {
+		v.helper.Warnf("verify user token signature: %v", err)
+		return nil, ErrInvalidSignature
+	}
+
+	claims, err := parseUserClaims(token, v.cfg.RolesClaim, v.cfg.ScopesClaim)
+	if err != nil {
+		v.helper.Errorf("parse user claims: %v", err)
+		return nil, ErrTokenParseFail
+	}
+
+	if claims.Issuer != v.cfg.Issuer {
+		v.helper.Warnf("user token issuer mismatch: got=%q want=%q", claims.Issuer, v.cfg.Issuer)
+		return nil, ErrInvalidIssuer
+	}
+	if !containsString(claims.Audience, v.cfg.Audience) {
+		v.helper.Warnf("user token audience mismatch: got=%q want=%q", claims.Audience, v.cfg.Audience)
+		return nil, ErrInvalidAudience
+	}
+	now := time.Now()
+	if now.Add(-v.cfg.ClockSkew).Unix() >= claims.ExpiresAt {
+		v.helper.Warnf("user token expired: exp=%v now=%v", time.Unix(claims.ExpiresAt, 0), now)
+		return nil, fmt.Errorf("%w: expired at %v", ErrTokenExpired, time.Unix(claims.ExpiresAt, 0))
+	}
+	return claims, nil
+}
+
+// Close 停止 JWKS 后台刷新。
+func (v *OIDCVerifier) Close() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.jwks != nil {
+		v.jwks.Close()
+	}
+	v.closed = true
+}
+
+// keySource 懒加载 JWKS 公钥源。discovery 在锁外执行并通过 singleflight 合并，
+// 锁只用于发布结果，慢速 discovery 不会阻塞 Close 或其他已完成初始化的读取。
+func (v *OIDCVerifier) keySource(ctx context.Context) (KeySource, error) {
+	v.mu.Lock()
+	keys, closed := v.keys, v.closed
+	v.mu.Unlock()
+	if keys != nil {
+		return keys, nil
+	}
+	if closed {
+		return nil, errVerifierClosed
+	}
+
+	ch := v.discovery.DoChan("discover", func() (interface{}, error) {
+		discoverCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultJWKSFetchTimeout)
+		defer cancel()
+		jwksURL, err := v.discover(discoverCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if v.closed {
+			return nil, errVerifierClosed
+		}
+		if v.keys == nil {
+			v.jwks = NewJWKSKeySource(jwksURL, WithJWKSHTTPClient(v.client), WithJWKSLogger(v.logger))
+			v.keys = v.jwks
+			v.helper.Infof("oidc discovery completed (issuer=%s jwks_uri=%s)", v.cfg.Issuer, jwksURL)
+		}
+		return v.keys, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(KeySource), nil
+	}
+}
+
+func (v *OIDCVerifier) discover(ctx context.Context) (string, error) {
+	url := strings.TrimSuffix(v.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("build discovery request: %w", err)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch discovery document: unexpected status %d", resp.StatusCode)
+	}
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return "", fmt.Errorf("decode discovery document: %w", err)
+	}
+	if doc.Issuer != v.cfg.Issuer {
+		return "", fmt.Errorf("discovery issuer mismatch: got=%q want=%q", doc.Issuer, v.cfg.Issuer)
+	}
+	if doc.JWKSURI == "" {
+		return "", fmt.Errorf("discovery document has no jwks_uri")
+	}
+	return doc.JWKSURI, nil
+}
+
+// User 返回 Kratos 服务端中间件，从独立 Header 读取终端用户 Token 并写入 UserClaims，
+// 可与 Server 中间件同时使用。
+func User(verifier *OIDCVerifier) middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			token, err := extractToken(ctx, verifier.cfg.HeaderKey)
+			if err != nil {
+				if err == ErrMissingToken {
+					if !verifier.cfg.Required {
+						return next(ctx, req)
+					}
+					return nil, ErrMissingUserToken
+				}
+				return nil, err
+			}
+
+			claims, err := verifier.Verify(ctx, token)
+			if err != nil {
+				return nil, err
+			}
+
+			ctx = NewUserContext(ctx, claims)
+			verifier.helper.Debugf("authenticated user sub=%s", claims.Subject)
+			return next(ctx, req)
+		}
+	}
+}
+
+func parseUserClaims(token, rolesClaim, scopesClaim string) (*UserClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid jwt format: expected 3 parts, got %d", len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshal claims: %w", err)
+	}
+
+	claims := &UserClaims{
+		Subject:   stringClaim(raw["sub"]),
+		Issuer:    stringClaim(raw["iss"]),
+		Audience:  stringsClaim(raw["aud"]),
+		Email:     stringClaim(raw["email"]),
+		Name:      stringClaim(raw["name"]),
+		IssuedAt:  int64Claim(raw["iat"]),
+		ExpiresAt: int64Claim(raw["exp"]),
+		Roles:     stringsClaim(lookupClaim(raw, rolesClaim)),
+		Scopes:    stringsClaim(lookupClaim(raw, scopesClaim)),
+		Raw:       raw,
+	}
+	claims.EmailVerified, _ = raw["email_verified"].(bool)
+	return claims, nil
+}
+
+// lookupClaim 按点分路径读取嵌套 claim。
+func lookupClaim(raw map[string]interface{}, path string) interface{} {
+	var cur interface{} = raw
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[key]
+	}
+	return cur
+}
+
+func stringClaim(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// stringsClaim 兼容数组与空格分隔字符串两种形式。
+func stringsClaim(v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		return strings.Fields(val)
+	case []interface{}:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func int64Claim(v interface{}) int64 {
+	f, _ := v.(float64)
+	return int64(f)
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package gcjwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GoogleIssuers 是 Google 签发 ID Token 时可能使用的 iss 取值。
+var GoogleIssuers = []string{"https://accounts.google.com", "accounts.google.com"}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// verifyTokenSignature 校验 RS256 签名：按头部 kid 从 KeySource 取公钥，
+// 对 "header.payload" 做 PKCS#1 v1.5 + SHA-256 验签。
+func verifyTokenSignature(ctx context.Context, token string, keys KeySource) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid jwt format: expected 3 parts, got %d", len(parts))
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("decode header: %w", err)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return fmt.Errorf("unmarshal header: %w", err)
+	}
+	if header.Alg != "RS256" {
+		return fmt.Errorf("unsupported alg %q", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+
+	key, err := keys.Key(ctx, header.Kid)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return fmt.Errorf("verify kid=%q: %w", header.Kid, err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package gclog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// truncatedSuffix marks captured bodies cut at the configured limit.
+const truncatedSuffix = "...(truncated)"
+
+// AccessLogOption customises AccessLog.
+type AccessLogOption func(*accessLogOptions)
+
+type accessLogOptions struct {
+	skip      []string
+	skipFunc  func(ctx context.Context, operation string) bool
+	bodyLimit int
+}
+
+// AccessLogSkip skips requests whose HTTP path or operation matches one of patterns.
+// A trailing "*" matches by prefix, e.g. "/healthz", "/grpc.health.v1.Health/*".
+func AccessLogSkip(patterns ...string) AccessLogOption {
+	return func(o *accessLogOptions) {
+		o.skip = append(o.skip, patterns...)
+	}
+}
+
+// AccessLogSkipFunc skips requests for which fn returns true.
+func AccessLogSkipFunc(fn func(ctx context.Context, operation string) bool) AccessLogOption {
+	return func(o *accessLogOptions) {
+		o.skipFunc = fn
+	}
+}
+
+// AccessLogBodies captures request and response bodies into jsonPayload.payload, each cut
+// at maxBytes. Bodies pass through the logger's Redactor; keep this off for sensitive APIs.
+func AccessLogBodies(maxBytes int) AccessLogOption {
+	return func(o *accessLogOptions) {
+		o.bodyLimit = maxBytes
+	}
+}
+
+// AccessLog returns a Kratos server middleware (HTTP and gRPC) that writes one entry per
+// request with a structured httpRequest, severity from SeverityFromHTTP and trace fields.
+// It works on either side of Recovery: a panic unwinding through it is logged as a 500
+// and re-raised for Recovery to handle.
+func AccessLog(logger log.Logger, opts ...AccessLogOption) middleware.Middleware {
+	cfg := accessLogOptions{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			httpReq, isHTTP := khttp.RequestFromServerContext(ctx)
+			path := tr.Operation()
+			if isHTTP {
+				path = httpReq.URL.Path
+			}
+			if cfg.skipped(ctx, tr.Operation(), path) {
+				return handler(ctx, req)
+			}
+
+			start := time.Now()
+			defer func() {
+				if r := recover(); r != nil {
+					cfg.log(ctx, logger, tr, httpReq, path, req, nil, errors.InternalServer("PANIC", fmt.Sprintf("panic: %v", r)), time.Since(start))
+					panic(r)
+				}
+			}()
+			reply, err = handler(ctx, req)
+			cfg.log(ctx, logger, tr, httpReq, path, req, reply, err, time.Since(start))
+			return reply, err
+		}
+	}
+}
+
+// log writes the access entry; httpReq is nil for gRPC.
+func (o *accessLogOptions) log(ctx context.Context, logger log.Logger, tr transport.Transporter, httpReq *http.Request, path string, req, reply interface{}, err error, latency time.Duration) {
+	code := http.StatusOK
+	payload := map[string]any{"operation": tr.Operation()}
+	if err != nil {
+		se := errors.FromError(err)
+		code = int(se.Code)
+		payload["error"] = err.Error()
+		if se.Reason != "" {
+			payload["reason"] = se.Reason
+		}
+	}
+
+	var entry *httpRequest
+	if httpReq != nil {
+		entry = httpRequestEntry(httpReq, code)
+	} else {
+		entry = grpcRequestEntry(ctx, tr, code)
+		payload["grpc_code"] = status.ToGRPCCode(code).String()
+	}
+	entry.Latency = formatDuration(latency)
+	if entry.RequestSize == "" {
+		if n := messageSize(req); n > 0 {
+			entry.RequestSize = strconv.Itoa(n)
+		}
+	}
+	if n := messageSize(reply); n > 0 && err == nil {
+		entry.ResponseSize = strconv.Itoa(n)
+	}
+	if o.bodyLimit > 0 {
+		payload["request_body"] = captureBody(req, o.bodyLimit)
+		if err == nil {
+			payload["response_body"] = captureBody(reply, o.bodyLimit)
+		}
+	}
+
+	_ = WithTrace(ctx, logger).Log(SeverityFromHTTP(code),
+		// The message carries only the path: query strings may hold tokens, so the full URL stays in httpRequest.
+		log.DefaultMessageKey, fmt.Sprintf("%s %s %d", entry.RequestMethod, path, code),
+		httpRequestKey, entry,
+		payloadKey, payload,
+	)
+}
+
+func (o *accessLogOptions) skipped(ctx context.Context, operation, path string) bool {
+	if o.skipFunc != nil && o.skipFunc(ctx, operation) {
+		return true
+	}
+	for _, pattern := range o.skip {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) || strings.HasPrefix(operation, prefix) {
+				return true
+			}
+			continue
+		}
+		if path == pattern || operation == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func httpRequestEntry(req *http.Request, code int) *httpRequest {
+	entry := &httpRequest{
+		RequestMethod: req.Method,
+		RequestURL:    req.URL.String(),
+		Status:        code,
+		UserAgent:     req.UserAgent(),
+		RemoteIP:      clientIP(req),
+		Referer:       req.Referer(),
+		Protocol:      req.Proto,
+	}
+	if req.ContentLength > 0 {
+		entry.RequestSize = strconv.FormatInt(req.ContentLength, 10)
+	}
+	return entry
+}
+
+// grpcRequestEntry describes a gRPC call the way Google front ends do: POST /pkg.Service/Method over HTTP/2.
+func grpcRequestEntry(ctx context.Context, tr transport.Transporter, code int) *httpRequest {
+	entry := &httpRequest{
+		RequestMethod: http.MethodPost,
+		RequestURL:    tr.Operation(),
+		Status:        code,
+		UserAgent:     tr.RequestHeader().Get("user-agent"),
+		Protocol:      "HTTP/2",
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry.RemoteIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(entry.RemoteIP); err == nil {
+			entry.RemoteIP = host
+		}
+	}
+	return entry
+}
+
+func messageSize(v interface{}) int {
+	if m, ok := v.(proto.Message); ok && m != nil {
+		return proto.Size(m)
+	}
+	return 0
+}
+
+func captureBody(v interface{}, limit int) string {
+	if v == nil {
+		return ""
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if m, ok := v.(proto.Message); ok {
+		data, err = protojson.Marshal(m)
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return fmt.Sprintf("<unencodable: %v>", err)
+	}
+	if len(data) > limit {
+		// Back off to a rune boundary so the excerpt stays valid UTF-8.
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(data[cut]) {
+			cut--
+		}
+		return string(data[:cut]) + truncatedSuffix
+	}
+	return string(data)
+}
@@ -0,0 +1,337 @@
+package gclog
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	defaultAsyncBufferSize    = 4096
+	defaultAsyncFlushInterval = time.Second
+)
+
+// OverflowPolicy decides what an AsyncWriter does when its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the logging goroutine wait for space (no loss, may stall callers).
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropDebugFirst evicts the oldest buffered DEBUG entry to make room;
+	// incoming DEBUG entries, or any entry when no DEBUG is buffered, are dropped.
+	OverflowDropDebugFirst
+	// OverflowDropNewest drops the incoming entry.
+	OverflowDropNewest
+)
+
+// ParseOverflowPolicy parses block/drop_debug_first/drop_newest; an empty string yields block.
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch s {
+	case "", "block":
+		return OverflowBlock, nil
+	case "drop_debug_first":
+		return OverflowDropDebugFirst, nil
+	case "drop_newest":
+		return OverflowDropNewest, nil
+	}
+	return OverflowBlock, fmt.Errorf("gclog: unknown overflow policy %q", s)
+}
+
+// AsyncOption customises an AsyncWriter.
+type AsyncOption func(*AsyncWriter)
+
+// AsyncBufferSize sets the number of buffered entries (default 4096).
+func AsyncBufferSize(n int) AsyncOption {
+	return func(a *AsyncWriter) {
+		if n > 0 {
+			a.buf = make([]asyncRecord, n)
+		}
+	}
+}
+
+// AsyncOverflow sets the overflow policy (default OverflowBlock).
+func AsyncOverflow(p OverflowPolicy) AsyncOption {
+	return func(a *AsyncWriter) {
+		a.policy = p
+	}
+}
+
+// AsyncFlushInterval sets how often buffered output is flushed to the underlying writer (default 1s).
+func AsyncFlushInterval(d time.Duration) AsyncOption {
+	return func(a *AsyncWriter) {
+		if d > 0 {
+			a.interval = d
+		}
+	}
+}
+
+// AsyncMeter sets the meter recording gclog_async_dropped_total (defaults to the global MeterProvider).
+func AsyncMeter(meter metric.Meter) AsyncOption {
+	return func(a *AsyncWriter) {
+		a.meter = meter
+	}
+}
+
+// WithAsync moves writes to a background goroutine backed by a bounded buffer.
+// Call Logger.Close (or the NewComponent cleanup) on shutdown to flush pending entries.
+func WithAsync(opts ...AsyncOption) Option {
+	return func(o *Options) {
+		o.async = append(o.async, opts...)
+		o.asyncEnabled = true
+	}
+}
+
+type asyncRecord struct {
+	level log.Level
+	data  []byte
+}
+
+// AsyncWriter serialises log lines on a single background goroutine.
+type AsyncWriter struct {
+	writeMu  sync.Mutex // guards out
+	out      *bufio.Writer
+	policy   OverflowPolicy
+	interval time.Duration
+	meter    metric.Meter
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	buf     []asyncRecord
+	head    int
+	count   int
+	closed  bool
+
+	wake    chan struct{}
+	flushCh chan chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+
+	dropped     atomic.Uint64
+	dropCounter metric.Int64Counter
+	writeErr    atomic.Pointer[error]
+}
+
+// NewAsyncWriter starts a background writer on w.
+func NewAsyncWriter(w io.Writer, opts ...AsyncOption) *AsyncWriter {
+	a := &AsyncWriter{
+		out:      bufio.NewWriterSize(w, 64*1024),
+		interval: defaultAsyncFlushInterval,
+		buf:      make([]asyncRecord, defaultAsyncBufferSize),
+		wake:     make(chan struct{}, 1),
+		flushCh:  make(chan chan struct{}),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.notFull = sync.NewCond(&a.mu)
+	meter := a.meter
+	if meter == nil {
+		meter = otel.GetMeterProvider().Meter("lingo-utils/gclog")
+	}
+	a.dropCounter, _ = meter.Int64Counter("gclog_async_dropped_total",
+		metric.WithDescription("Log entries dropped because the async buffer was full."))
+	go a.run()
+	return a
+}
+
+// Write enqueues p as an INFO entry; it implements io.Writer.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+	if err := a.enqueue(log.LevelInfo, data); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of entries dropped so far.
+func (a *AsyncWriter) Dropped() uint64 { return a.dropped.Load() }
+
+// Err returns the last error from the underlying writer, if any.
+func (a *AsyncWriter) Err() error {
+	if err := a.writeErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// enqueue takes ownership of data.
+func (a *AsyncWriter) enqueue(level log.Level, data []byte) error {
+	a.mu.Lock()
+	for !a.closed && a.count == len(a.buf) {
+		switch a.policy {
+		case OverflowDropNewest:
+			a.mu.Unlock()
+			a.drop(level)
+			return nil
+		case OverflowDropDebugFirst:
+			if level == log.LevelDebug || !a.evictDebugLocked() {
+				a.mu.Unlock()
+				a.drop(level)
+				return nil
+			}
+		default:
+			a.notFull.Wait()
+		}
+	}
+	if a.closed {
+		a.mu.Unlock()
+		// 关闭后同步写出，等待最后一次 drain 完成以保持顺序。
+		<-a.done
+		a.writeMu.Lock()
+		defer a.writeMu.Unlock()
+		if _, err := a.out.Write(data); err != nil {
+			return err
+		}
+		return a.out.Flush()
+	}
+	a.buf[(a.head+a.count)%len(a.buf)] = asyncRecord{level: level, data: data}
+	a.count++
+	a.mu.Unlock()
+
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// evictDebugLocked removes the oldest buffered DEBUG record, preserving order of the rest.
+func (a *AsyncWriter) evictDebugLocked() bool {
+	n := len(a.buf)
+	for i := 0; i < a.count; i++ {
+		if a.buf[(a.head+i)%n].level != log.LevelDebug {
+			continue
+		}
+		for j := i; j < a.count-1; j++ {
+			a.buf[(a.head+j)%n] = a.buf[(a.head+j+1)%n]
+		}
+		a.buf[(a.head+a.count-1)%n] = asyncRecord{}
+		a.count--
+		a.drop(log.LevelDebug)
+		return true
+	}
+	return false
+}
+
+func (a *AsyncWriter) drop(level log.Level) {
+	a.dropped.Add(1)
+	if a.dropCounter != nil {
+		a.dropCounter.Add(context.Background(), 1, metric.WithAttributes(attribute.String("severity", severityFromLevel(level))))
+	}
+}
+
+func (a *AsyncWriter) run() {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.wake:
+			a.drain(false)
+		case <-ticker.C:
+			a.drain(true)
+		case req := <-a.flushCh:
+			a.drain(true)
+			close(req)
+		case <-a.closing:
+			a.drain(true)
+			close(a.done)
+			return
+		}
+	}
+}
+
+// drain writes every buffered record; flush also pushes bufio output to the underlying writer.
+func (a *AsyncWriter) drain(flush bool) {
+	batch := make([]asyncRecord, 0, 64)
+	for {
+		a.mu.Lock()
+		if a.count == 0 {
+			a.mu.Unlock()
+			if flush {
+				a.writeMu.Lock()
+				a.setErr(a.out.Flush())
+				a.writeMu.Unlock()
+			}
+			return
+		}
+		batch = batch[:0]
+		n := len(a.buf)
+		for i := 0; i < a.count; i++ {
+			idx := (a.head + i) % n
+			batch = append(batch, a.buf[idx])
+			a.buf[idx] = asyncRecord{}
+		}
+		a.head, a.count = 0, 0
+		a.notFull.Broadcast()
+		a.mu.Unlock()
+
+		a.writeMu.Lock()
+		for _, rec := range batch {
+			if _, err := a.out.Write(rec.data); err != nil {
+				a.setErr(err)
+			}
+		}
+		a.writeMu.Unlock()
+	}
+}
+
+func (a *AsyncWriter) setErr(err error) {
+	if err != nil {
+		a.writeErr.Store(&err)
+	}
+}
+
+// Flush blocks until every entry enqueued before the call reaches the underlying writer.
+func (a *AsyncWriter) Flush() error {
+	req := make(chan struct{})
+	select {
+	case a.flushCh <- req:
+		<-req
+	case <-a.done:
+	}
+	return a.Err()
+}
+
+// Close flushes pending entries and stops the background goroutine. Later writes are
+// performed synchronously. The underlying writer is not closed.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return a.Err()
+	}
+	a.closed = true
+	a.notFull.Broadcast()
+	a.mu.Unlock()
+	close(a.closing)
+	<-a.done
+	return a.Err()
+}
+
+// levelFromSeverity maps a Cloud Logging severity back to the Kratos level for overflow decisions.
+func levelFromSeverity(severity string) log.Level {
+	switch severity {
+	case "DEBUG":
+		return log.LevelDebug
+	case "WARNING":
+		return log.LevelWarn
+	case "ERROR":
+		return log.LevelError
+	case "CRITICAL":
+		return log.LevelFatal
+	default:
+		return log.LevelInfo
+	}
+}
@@ -0,0 +1,236 @@
+package gclog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format selects how entries are encoded.
+type Format int
+
+const (
+	// FormatAuto picks FormatConsole when the writer is a terminal, FormatJSON otherwise (default).
+	FormatAuto Format = iota
+	// FormatJSON writes one Cloud Logging JSON object per line.
+	FormatJSON
+	// FormatConsole writes one human-readable line per entry for local development.
+	FormatConsole
+)
+
+// String implements fmt.Stringer.
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatConsole:
+		return "console"
+	default:
+		return "auto"
+	}
+}
+
+// ParseFormat parses auto/json/console; an empty string yields auto.
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "", "auto":
+		return FormatAuto, nil
+	case "json":
+		return FormatJSON, nil
+	case "console":
+		return FormatConsole, nil
+	}
+	return FormatAuto, fmt.Errorf("gclog: unknown format %q", s)
+}
+
+// WithFormat overrides the output format. Key validation and redaction are identical in every format.
+func WithFormat(f Format) Option {
+	return func(o *Options) {
+		o.Format = f
+	}
+}
+
+// isTerminal reports whether w is a character device such as an interactive stdout.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// resolveFormat returns the effective format and whether ANSI colours are used.
+// Colours require a terminal and honour NO_COLOR (https://no-color.org).
+func resolveFormat(f Format, w io.Writer) (Format, bool) {
+	tty := isTerminal(w)
+	if f == FormatAuto {
+		if tty {
+			f = FormatConsole
+		} else {
+			f = FormatJSON
+		}
+	}
+	return f, f == FormatConsole && tty && os.Getenv("NO_COLOR") == ""
+}
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+)
+
+func severityColor(severity string) string {
+	switch severity {
+	case "DEBUG":
+		return ansiDim
+	case "WARNING":
+		return ansiYellow
+	case "ERROR":
+		return ansiRed
+	case "CRITICAL":
+		return ansiMagenta
+	default:
+		return ansiBlue
+	}
+}
+
+// encodeConsole renders entry as
+//
+//	15:04:05.000 INFO  message caller=file.go:12 label=v key=v http="GET /x 200 0.010s" op=<id> trace=<id>
+//
+// Payload and labels are sorted by key so lines stay stable between runs. A reported
+// error's stack follows on indented lines so the entry's first line stays grep-friendly.
+func encodeConsole(entry logEntry, color bool) []byte {
+	var b bytes.Buffer
+	paint := func(code, s string) {
+		if color {
+			b.WriteString(code)
+			b.WriteString(s)
+			b.WriteString(ansiReset)
+			return
+		}
+		b.WriteString(s)
+	}
+
+	ts := entry.Timestamp
+	if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+		ts = t.Local().Format("15:04:05.000")
+	}
+	paint(ansiDim, ts)
+	b.WriteByte(' ')
+	paint(severityColor(entry.Severity), fmt.Sprintf("%-5s", consoleSeverity(entry.Severity)))
+	b.WriteByte(' ')
+	b.WriteString(entry.Message)
+
+	if caller := shortCaller(entry); caller != "" {
+		writeField(&b, color, callerKey, caller)
+	}
+	for _, k := range sortedKeys(entry.Labels) {
+		if k == callerKey {
+			continue
+		}
+		writeField(&b, color, k, entry.Labels[k])
+	}
+	for _, k := range sortedKeys(entry.JSONPayload) {
+		writeField(&b, color, k, consoleValue(entry.JSONPayload[k]))
+	}
+	if req := entry.HTTPRequest; req != nil {
+		parts := []string{req.RequestMethod, req.RequestURL}
+		if req.Status != 0 {
+			parts = append(parts, fmt.Sprint(req.Status))
+		}
+		if req.Latency != "" {
+			parts = append(parts, req.Latency)
+		}
+		writeField(&b, color, "http", strings.TrimSpace(strings.Join(parts, " ")))
+	}
+	if entry.Operation != nil {
+		writeField(&b, color, "op", entry.Operation.ID)
+	}
+	if entry.Trace != "" {
+		writeField(&b, color, "trace", entry.Trace[strings.LastIndexByte(entry.Trace, '/')+1:])
+	}
+	b.WriteByte('\n')
+	for _, line := range strings.Split(strings.TrimRight(entry.stack, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		b.WriteString("    ")
+		paint(ansiDim, line)
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+func consoleSeverity(severity string) string {
+	switch severity {
+	case "WARNING":
+		return "WARN"
+	case "CRITICAL":
+		return "FATAL"
+	case "":
+		return "INFO"
+	}
+	return severity
+}
+
+// shortCaller prefers the Kratos caller label and falls back to dir/file:line from sourceLocation.
+func shortCaller(entry logEntry) string {
+	if caller := entry.Labels[callerKey]; caller != "" {
+		return caller
+	}
+	if src := entry.SourceLocation; src != nil && src.File != "" {
+		return fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(src.File)), filepath.Base(src.File), src.Line)
+	}
+	return ""
+}
+
+func writeField(b *bytes.Buffer, color bool, key, value string) {
+	b.WriteByte(' ')
+	if color {
+		b.WriteString(ansiDim)
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(ansiReset)
+	} else {
+		b.WriteString(key)
+		b.WriteByte('=')
+	}
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+	b.WriteString(value)
+}
+
+func consoleValue(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case fmt.Stringer:
+		return t.String()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
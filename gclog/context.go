@@ -0,0 +1,34 @@
+package gclog
+
+import "context"
+
+type contextLabelsKey struct{}
+
+// ContextWithLabels 将 labels 合并进 ctx，通过 NewComponent 构造的 logger 在携带该 ctx
+// 记录日志（log.WithContext / Helper.WithContext）时会自动附加这些 label。
+func ContextWithLabels(ctx context.Context, labels map[string]string) context.Context {
+	if len(labels) == 0 {
+		return ctx
+	}
+	existing := LabelsFromContext(ctx)
+	merged := make(map[string]string, len(existing)+len(labels))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		if k == "" {
+			continue
+		}
+		merged[k] = v
+	}
+	return context.WithValue(ctx, contextLabelsKey{}, merged)
+}
+
+// LabelsFromContext 返回 ctx 中通过 ContextWithLabels 写入的 label，调用方不应修改返回值。
+func LabelsFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	labels, _ := ctx.Value(contextLabelsKey{}).(map[string]string)
+	return labels
+}
@@ -0,0 +1,153 @@
+package gclog
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// reportedErrorEventType marks an entry for Cloud Error Reporting ingestion.
+const reportedErrorEventType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+
+const maxStackDepth = 32
+
+// DisableErrorReporting keeps ERROR entries in the plain format (no @type / stack in message).
+func DisableErrorReporting() Option {
+	return func(o *Options) {
+		o.DisableErrorReporting = true
+	}
+}
+
+// WithStack records the caller's stack on err so the Error Reporting entry points at
+// where the error was created rather than where it was logged. Errors that already
+// carry a stack are returned unchanged.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+	var se *stackError
+	if errors.As(err, &se) {
+		return err
+	}
+	return &stackError{err: err, pcs: callers(3)}
+}
+
+type stackError struct {
+	err error
+	pcs []uintptr
+}
+
+func (e *stackError) Error() string { return e.err.Error() }
+func (e *stackError) Unwrap() error { return e.err }
+
+// panicError is logged by Recovery; its stack starts at the panicking frame.
+type panicError struct {
+	value any
+	pcs   []uintptr
+}
+
+func newPanicError(value any) *panicError {
+	// 跳过 Recovery 的 defer 与 runtime.gopanic 等帧，使栈顶为发生 panic 的函数。
+	pcs := trimFrames(callers(3), func(function string) bool {
+		return isLoggerFrame(function) || strings.HasPrefix(function, "runtime.")
+	})
+	return &panicError{value: value, pcs: pcs}
+}
+
+func (e *panicError) Error() string { return fmt.Sprintf("panic: %v", e.value) }
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip, pcs)
+	return pcs[:n]
+}
+
+// errorStack returns the innermost stack recorded on err, or nil.
+func errorStack(err error) []uintptr {
+	var pcs []uintptr
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch t := e.(type) {
+		case *stackError:
+			pcs = t.pcs
+		case *panicError:
+			pcs = t.pcs
+		}
+	}
+	return pcs
+}
+
+// logSiteStack captures the stack of the code calling the logger, skipping gclog and Kratos frames.
+func logSiteStack() []uintptr {
+	return trimFrames(callers(3), isLoggerFrame)
+}
+
+// trimFrames drops leading program counters whose function matches skip.
+func trimFrames(pcs []uintptr, skip func(function string) bool) []uintptr {
+	for i := range pcs {
+		frame, _ := runtime.CallersFrames(pcs[i : i+1]).Next()
+		if !skip(frame.Function) {
+			return pcs[i:]
+		}
+	}
+	return pcs
+}
+
+func isLoggerFrame(function string) bool {
+	return strings.HasPrefix(function, "github.com/bionicotaku/lingo-utils/gclog.") ||
+		strings.HasPrefix(function, "github.com/go-kratos/kratos/v2/log.")
+}
+
+// formatStack renders pcs like runtime/debug.Stack so Error Reporting can parse it,
+// and returns the top frame as the report location.
+func formatStack(pcs []uintptr) (string, *reportLocation) {
+	var (
+		b   strings.Builder
+		loc *reportLocation
+	)
+	b.WriteString("goroutine 1 [running]:\n")
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			if loc == nil {
+				loc = &reportLocation{FilePath: frame.File, LineNumber: frame.Line, FunctionName: frame.Function}
+			}
+			fmt.Fprintf(&b, "%s(...)\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String(), loc
+}
+
+// reportError rewrites entry into the ReportedErrorEvent shape.
+func reportError(entry *logEntry, errObj error, errValue string) {
+	pcs := errorStack(errObj)
+	if len(pcs) == 0 {
+		pcs = logSiteStack()
+	}
+	stack, loc := formatStack(pcs)
+
+	head := errValue
+	if entry.Message != noMessage && entry.Message != errValue {
+		head = entry.Message + ": " + errValue
+	}
+	entry.Type = reportedErrorEventType
+	entry.Message = head
+	entry.stack = stack
+	if loc != nil {
+		entry.Context = &errorContext{ReportLocation: loc}
+	}
+}
+
+type errorContext struct {
+	ReportLocation *reportLocation `json:"reportLocation,omitempty"`
+}
+
+type reportLocation struct {
+	FilePath     string `json:"filePath"`
+	LineNumber   int    `json:"lineNumber"`
+	FunctionName string `json:"functionName"`
+}
@@ -37,12 +37,13 @@ func AppendLabels(kvs []interface{}, labels map[string]string) []interface{} {
 	return append(kvs, labelsKey, labels)
 }
 
-// WithTrace binds trace/span fields to the logger while preserving context.
+// WithTrace binds trace/span fields and the sampling flag to the logger while preserving context.
 func WithTrace(ctx context.Context, base log.Logger) log.Logger {
 	kvs := AppendTrace(ctx, nil)
 	if len(kvs) == 0 {
 		return log.WithContext(ctx, base)
 	}
+	kvs = append(kvs, sampledKey, trace.SpanContextFromContext(ctx).IsSampled())
 	return log.WithContext(ctx, log.With(base, kvs...))
 }
 
@@ -86,12 +87,13 @@ func WithStatus(logger log.Logger, status string) log.Logger {
 	return log.With(logger, payloadKey, map[string]any{"status": status})
 }
 
-// WithError attaches an error string to the payload.
+// WithError attaches an error to the payload; ERROR entries carrying it are emitted
+// in the Error Reporting format, using the stack recorded by WithStack when present.
 func WithError(logger log.Logger, err error) log.Logger {
 	if err == nil {
 		return logger
 	}
-	return log.With(logger, errorKey, err.Error())
+	return log.With(logger, errorKey, err)
 }
 
 // WithMetadata attaches metadata key/value pairs (e.g. transport headers) to the payload.
@@ -116,18 +118,7 @@ func WithHTTPRequest(logger log.Logger, req *http.Request, status int, latency t
 	if req == nil {
 		return logger
 	}
-	httpReq := &httpRequest{
-		RequestMethod: req.Method,
-		RequestURL:    req.URL.String(),
-		Status:        status,
-		UserAgent:     req.UserAgent(),
-		RemoteIP:      clientIP(req),
-		Referer:       req.Referer(),
-		Protocol:      req.Proto,
-	}
-	if req.ContentLength > 0 {
-		httpReq.RequestSize = strconv.FormatInt(req.ContentLength, 10)
-	}
+	httpReq := httpRequestEntry(req, status)
 	if latency > 0 {
 		httpReq.Latency = formatDuration(latency)
 	}
@@ -241,11 +232,13 @@ func (h *Helper) InfoWithPayload(msg string, payload map[string]any, kvs ...inte
 	h.helper.Log(log.LevelInfo, args...)
 }
 
-// RequestLogger 组合 trace + caller + labels + payload。
+// RequestLogger 组合 trace + caller + 上下文 labels + labels + 上下文 operation + payload。
 func RequestLogger(ctx context.Context, base log.Logger, caller string, labels map[string]string, payload map[string]any) *Helper {
 	logger := WithTrace(ctx, base)
 	logger = WithCaller(logger, caller)
+	logger = WithLabels(logger, LabelsFromContext(ctx))
 	logger = WithLabels(logger, labels)
+	logger = WithOperation(ctx, logger)
 	logger = WithPayload(logger, payload)
 	return NewHelper(logger)
 }
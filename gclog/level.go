@@ -0,0 +1,237 @@
+package gclog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// levelLoggerKey is the keyval whose value selects per-logger overrides. It is distinct from
+// "component", which Kratos logging middleware sets to the transport ("http"/"grpc").
+const levelLoggerKey = "logger"
+
+// LevelVar is a runtime-adjustable minimum level with per-logger overrides.
+// It is safe for concurrent use; readers never block.
+//
+// Overrides are matched against the "logger" field of an entry using dotted
+// prefixes: an override for "gcjwt" applies to "gcjwt.jwks" unless a longer
+// override such as "gcjwt.jwks" exists.
+type LevelVar struct {
+	min       atomic.Int32
+	mu        sync.Mutex // serialises override writers
+	overrides atomic.Pointer[map[string]log.Level]
+}
+
+// NewLevelVar returns a LevelVar with the given global minimum level.
+func NewLevelVar(level log.Level) *LevelVar {
+	v := &LevelVar{}
+	v.min.Store(int32(level))
+	empty := map[string]log.Level{}
+	v.overrides.Store(&empty)
+	return v
+}
+
+// Level returns the global minimum level.
+func (v *LevelVar) Level() log.Level { return log.Level(v.min.Load()) }
+
+// SetLevel updates the global minimum level.
+func (v *LevelVar) SetLevel(level log.Level) { v.min.Store(int32(level)) }
+
+// SetOverride sets the minimum level for a logger name (and its dotted children).
+func (v *LevelVar) SetOverride(name string, level log.Level) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	current := *v.overrides.Load()
+	next := make(map[string]log.Level, len(current)+1)
+	for k, l := range current {
+		next[k] = l
+	}
+	next[name] = level
+	v.overrides.Store(&next)
+}
+
+// RemoveOverride drops the override for name; entries fall back to the global level.
+func (v *LevelVar) RemoveOverride(name string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	current := *v.overrides.Load()
+	if _, ok := current[name]; !ok {
+		return
+	}
+	next := make(map[string]log.Level, len(current))
+	for k, l := range current {
+		if k != name {
+			next[k] = l
+		}
+	}
+	v.overrides.Store(&next)
+}
+
+// Overrides returns a copy of the per-logger overrides.
+func (v *LevelVar) Overrides() map[string]log.Level {
+	current := *v.overrides.Load()
+	out := make(map[string]log.Level, len(current))
+	for k, l := range current {
+		out[k] = l
+	}
+	return out
+}
+
+// Enabled reports whether an entry at level from the named logger should be written.
+func (v *LevelVar) Enabled(logger string, level log.Level) bool {
+	return level >= v.effective(logger)
+}
+
+func (v *LevelVar) effective(logger string) log.Level {
+	overrides := *v.overrides.Load()
+	if len(overrides) > 0 && logger != "" {
+		for name := logger; ; {
+			if l, ok := overrides[name]; ok {
+				return l
+			}
+			idx := strings.LastIndexByte(name, '.')
+			if idx < 0 {
+				break
+			}
+			name = name[:idx]
+		}
+	}
+	return v.Level()
+}
+
+func (v *LevelVar) hasOverrides() bool { return len(*v.overrides.Load()) > 0 }
+
+// ParseLevel parses debug/info/warn(ing)/error/fatal (case-insensitive).
+// Unlike log.ParseLevel it rejects unknown values instead of defaulting to INFO.
+func ParseLevel(s string) (log.Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
+		return log.LevelDebug, nil
+	case "INFO":
+		return log.LevelInfo, nil
+	case "WARN", "WARNING":
+		return log.LevelWarn, nil
+	case "ERROR":
+		return log.LevelError, nil
+	case "FATAL":
+		return log.LevelFatal, nil
+	}
+	return log.LevelInfo, fmt.Errorf("gclog: unknown log level %q", s)
+}
+
+type levelState struct {
+	Level     string            `json:"level"`
+	Overrides map[string]string `json:"overrides,omitempty"`
+}
+
+// LevelHandler exposes v over HTTP for runtime control.
+//
+//	GET                                  -> current state as JSON
+//	PUT/POST ?level=debug                -> set the global level
+//	PUT/POST ?logger=gcpubsub&level=warn -> set a logger override
+//	DELETE   ?logger=gcpubsub            -> remove a logger override
+//
+// Mount it on an internal/admin listener only; it performs no authentication.
+func LevelHandler(v *LevelVar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSpace(r.FormValue(levelLoggerKey))
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			level, err := ParseLevel(r.FormValue("level"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if name != "" {
+				v.SetOverride(name, level)
+			} else {
+				v.SetLevel(level)
+			}
+		case http.MethodDelete:
+			if name == "" {
+				http.Error(w, "gclog: logger is required", http.StatusBadRequest)
+				return
+			}
+			v.RemoveOverride(name)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		state := levelState{Level: v.Level().String()}
+		if overrides := v.Overrides(); len(overrides) > 0 {
+			state.Overrides = make(map[string]string, len(overrides))
+			for k, l := range overrides {
+				state.Overrides[k] = l.String()
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(state)
+	})
+}
+
+// ToggleDebugOnSignal flips the global level between DEBUG and its previous value
+// (INFO if it was already DEBUG) each time one of sigs (typically syscall.SIGUSR1)
+// is received. The returned function stops listening.
+func ToggleDebugOnSignal(v *LevelVar, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		return func() {}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		previous := log.LevelInfo
+		for {
+			select {
+			case <-ch:
+				if current := v.Level(); current != log.LevelDebug {
+					previous = current
+					v.SetLevel(log.LevelDebug)
+				} else {
+					v.SetLevel(previous)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// parseLevelOverrides converts a logger→level string map into typed levels.
+func parseLevelOverrides(in map[string]string) (map[string]log.Level, error) {
+	out := make(map[string]log.Level, len(in))
+	keys := make([]string, 0, len(in))
+	for k := range in {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		level, err := ParseLevel(in[k])
+		if err != nil {
+			return nil, fmt.Errorf("level override %q: %w", k, err)
+		}
+		out[k] = level
+	}
+	return out, nil
+}
@@ -9,15 +9,20 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
 )
 
 const (
 	messageKey     = "message"
+	noMessage      = "<no message>"
 	traceKey       = "trace_id"
 	spanKey        = "span_id"
+	sampledKey     = "trace_sampled"
 	callerKey      = "caller"
 	payloadKey     = "payload"
 	labelsKey      = "labels"
@@ -30,6 +35,8 @@ var (
 		"kind":      {},
 		"component": {},
 		"operation": {},
+		// levelLoggerKey names the library logger for level overrides.
+		levelLoggerKey: {},
 	}
 	kratosPayloadFields = map[string]struct{}{
 		"args":    {},
@@ -42,16 +49,39 @@ var (
 
 // Options defines logger configuration parameters.
 type Options struct {
-	Service              string
-	Version              string
-	Environment          string
+	Service     string
+	Version     string
+	Environment string
+	// ProjectID qualifies trace IDs as projects/<ProjectID>/traces/<ID>; defaults to $GOOGLE_CLOUD_PROJECT.
+	ProjectID            string
 	StaticLabels         map[string]string
 	InstanceID           string
 	DisableInstanceID    bool
 	Writer               io.Writer
 	EnableSourceLocation bool
 	LabelNormalizer      func(map[string]string) map[string]string
-
+	// Level controls filtering at runtime; NewLogger creates one (DEBUG) when nil.
+	Level *LevelVar
+	// UnknownKeys handles keys outside the allowlist; the default folds them into jsonPayload.
+	UnknownKeys UnknownKeyPolicy
+	Meter       metric.Meter
+	// Redactor scrubs secrets/PII from message, error, payload and labels; nil disables redaction.
+	Redactor *Redactor
+	// DisableErrorReporting skips the Error Reporting format for ERROR entries with an error.
+	DisableErrorReporting bool
+	// DisableInsertID skips the generated logging.googleapis.com/insertId.
+	DisableInsertID bool
+	// LoggerProvider additionally receives every written entry as an OTel log record; nil disables the bridge.
+	LoggerProvider otellog.LoggerProvider
+	// Format selects JSON or console output; FormatAuto uses console on a terminal.
+	Format Format
+	// Sampler limits repeated low-severity entries; nil writes everything.
+	Sampler *Sampler
+
+	asyncEnabled     bool
+	async            []AsyncOption
+	minLevel         *log.Level
+	levelOverrides   map[string]log.Level
 	extraAllowedKeys map[string]struct{}
 	extraLabelKeys   map[string]struct{}
 }
@@ -80,6 +110,13 @@ func WithEnvironment(env string) Option {
 	}
 }
 
+// WithProjectID sets the GCP project used to format the Cloud Logging trace field.
+func WithProjectID(projectID string) Option {
+	return func(o *Options) {
+		o.ProjectID = strings.TrimSpace(projectID)
+	}
+}
+
 // WithStaticLabels registers constant labels that apply to all entries.
 func WithStaticLabels(labels map[string]string) Option {
 	return func(o *Options) {
@@ -133,6 +170,36 @@ func WithLabelNormalizer(fn func(map[string]string) map[string]string) Option {
 	}
 }
 
+// WithMinLevel drops entries below level (default DEBUG, i.e. everything is written).
+func WithMinLevel(level log.Level) Option {
+	return func(o *Options) {
+		o.minLevel = &level
+	}
+}
+
+// WithLevelOverride sets the minimum level for entries whose "logger" field
+// equals name or is a dotted child of it (e.g. "gcpubsub" at WARN).
+func WithLevelOverride(name string, level log.Level) Option {
+	return func(o *Options) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		if o.levelOverrides == nil {
+			o.levelOverrides = make(map[string]log.Level)
+		}
+		o.levelOverrides[name] = level
+	}
+}
+
+// WithLevelVar shares a LevelVar so levels can be changed at runtime (see LevelHandler).
+// WithMinLevel / WithLevelOverride are applied on top of it when the logger is built.
+func WithLevelVar(v *LevelVar) Option {
+	return func(o *Options) {
+		o.Level = v
+	}
+}
+
 // WithAllowedKeys registers additional key names accepted by the logger.
 // Values written with these keys将被合并进 jsonPayload 顶层。
 func WithAllowedKeys(keys ...string) Option {
@@ -187,6 +254,9 @@ func ValidateOptions(opts *Options) error {
 	if opts.Writer == nil {
 		opts.Writer = os.Stdout
 	}
+	if opts.ProjectID == "" {
+		opts.ProjectID = strings.TrimSpace(os.Getenv(projectEnvVar))
+	}
 	if opts.StaticLabels == nil {
 		opts.StaticLabels = make(map[string]string)
 	}
@@ -195,6 +265,15 @@ func ValidateOptions(opts *Options) error {
 			opts.InstanceID = host
 		}
 	}
+	if opts.Level == nil {
+		opts.Level = NewLevelVar(log.LevelDebug)
+	}
+	if opts.minLevel != nil {
+		opts.Level.SetLevel(*opts.minLevel)
+	}
+	for name, level := range opts.levelOverrides {
+		opts.Level.SetOverride(name, level)
+	}
 	return nil
 }
 
@@ -228,9 +307,27 @@ func NewLogger(opts ...Option) (log.Logger, error) {
 		}
 		payloadKeys[k] = struct{}{}
 	}
+	format, color := resolveFormat(cfg.Format, cfg.Writer)
+	w := cfg.Writer
+	var async *AsyncWriter
+	if cfg.asyncEnabled {
+		asyncOpts := cfg.async
+		if cfg.Meter != nil {
+			asyncOpts = append([]AsyncOption{AsyncMeter(cfg.Meter)}, asyncOpts...)
+		}
+		async = NewAsyncWriter(cfg.Writer, asyncOpts...)
+		w = async
+	}
 	l := &Logger{
 		opts:         *cfg,
-		w:            cfg.Writer,
+		w:            w,
+		async:        async,
+		insertIDs:    insertIDGenerator(cfg.DisableInsertID),
+		otel:         newOTelLogger(cfg.LoggerProvider),
+		format:       format,
+		color:        color,
+		level:        cfg.Level,
+		unknownKeys:  newUnknownKeyCounter(cfg.Meter),
 		staticLabels: staticLabels,
 		labelKeys:    labelKeys,
 		payloadKeys:  payloadKeys,
@@ -238,9 +335,11 @@ func NewLogger(opts ...Option) (log.Logger, error) {
 			log.DefaultMessageKey: {},
 			traceKey:              {},
 			spanKey:               {},
+			sampledKey:            {},
 			callerKey:             {},
 			payloadKey:            {},
 			labelsKey:             {},
+			operationKey:          {},
 			httpRequestKey:        {},
 			errorKey:              {},
 		},
@@ -251,6 +350,11 @@ func NewLogger(opts ...Option) (log.Logger, error) {
 	for key := range payloadKeys {
 		l.allowedKeys[key] = struct{}{}
 	}
+	if cfg.Sampler != nil {
+		l.summaryStop = make(chan struct{})
+		l.summaryDone = make(chan struct{})
+		go l.runSampleSummary(cfg.Sampler.summaryEvery, l.summaryStop, l.summaryDone)
+	}
 	return l, nil
 }
 
@@ -258,11 +362,25 @@ func NewLogger(opts ...Option) (log.Logger, error) {
 type Logger struct {
 	opts         Options
 	w            io.Writer
+	async        *AsyncWriter
+	insertIDs    *insertIDs
+	otel         otellog.Logger
+	format       Format
+	color        bool
+	level        *LevelVar
+	project      atomic.Pointer[string] // resolved in the background when ProjectID is unset
+	unknownKeys  metric.Int64Counter
+	warnedKeys   sync.Map
 	mu           sync.Mutex
 	staticLabels map[string]string
 	allowedKeys  map[string]struct{}
 	labelKeys    map[string]struct{}
 	payloadKeys  map[string]struct{}
+
+	// summaryStop/summaryDone drive the sampling summary ticker; nil without a Sampler.
+	summaryStop chan struct{}
+	summaryDone chan struct{}
+	closeOnce   sync.Once
 }
 
 // Log implements the Kratos log.Logger interface.
@@ -270,16 +388,25 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
 	if len(keyvals)%2 != 0 {
 		keyvals = append(keyvals, nil)
 	}
+	if !l.enabled(level, keyvals) {
+		return nil
+	}
+	if l.opts.Sampler != nil && !l.sample(level, keyvals) {
+		return nil
+	}
 
 	var (
 		msg           string
 		traceID       string
 		spanID        string
+		sampled       bool
 		caller        string
 		customPayload map[string]any
 		customLabels  map[string]string
 		httpReq       *httpRequest
+		operation     *Operation
 		errValue      string
+		errObj        error
 		extraJSON     map[string]any
 	)
 
@@ -290,7 +417,13 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
 			continue
 		}
 		if _, allowed := l.allowedKeys[key]; !allowed {
-			return fmt.Errorf("gclog: unsupported log field %q", key)
+			keep, err := l.unknownKey(key)
+			if err != nil {
+				return err
+			}
+			if !keep {
+				continue
+			}
 		}
 		val := keyvals[i+1]
 		switch key {
@@ -300,6 +433,8 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
 			traceID, _ = val.(string)
 		case spanKey:
 			spanID, _ = val.(string)
+		case sampledKey:
+			sampled, _ = val.(bool)
 		case callerKey:
 			caller, _ = val.(string)
 		case payloadKey:
@@ -330,6 +465,10 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
 					customLabels[lk] = fmt.Sprint(lv)
 				}
 			}
+		case operationKey:
+			if op, ok := val.(*Operation); ok && op != nil {
+				operation = op
+			}
 		case httpRequestKey:
 			switch v := val.(type) {
 			case *httpRequest:
@@ -340,6 +479,7 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
 			}
 		case errorKey:
 			if val != nil {
+				errObj, _ = val.(error)
 				errValue = fmt.Sprint(val)
 			}
 		default:
@@ -371,7 +511,20 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
 	}
 
 	if msg == "" {
-		msg = "<no message>"
+		msg = noMessage
+	}
+	if r := l.opts.Redactor; r != nil {
+		msg = r.String(msg)
+		errValue = r.String(errValue)
+		customPayload = r.Map(customPayload)
+		extraJSON = r.Map(extraJSON)
+		customLabels = r.Labels(customLabels)
+		if httpReq != nil {
+			redacted := *httpReq
+			redacted.RequestURL = r.String(redacted.RequestURL)
+			redacted.Referer = r.String(redacted.Referer)
+			httpReq = &redacted
+		}
 	}
 
 	entry := logEntry{
@@ -389,6 +542,7 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
 
 	entry.Trace = l.composeTrace(traceID)
 	entry.SpanID = spanID
+	entry.TraceSampled = sampled && entry.Trace != ""
 
 	if l.opts.EnableSourceLocation {
 		if src := captureSourceLocation(); src != nil {
@@ -399,6 +553,13 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
 	if httpReq != nil {
 		entry.HTTPRequest = httpReq
 	}
+	if operation != nil {
+		entry.Operation = operation.entry()
+	}
+
+	if !l.opts.DisableErrorReporting && level >= log.LevelError && errValue != "" {
+		reportError(&entry, errObj, errValue)
+	}
 
 	labels := l.composeLabels(caller, customLabels)
 	if len(labels) > 0 {
@@ -428,15 +589,43 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
 	return l.write(entry)
 }
 
+// LevelVar returns the level control used by the logger.
+func (l *Logger) LevelVar() *LevelVar { return l.level }
+
+func (l *Logger) enabled(level log.Level, keyvals []interface{}) bool {
+	if !l.level.hasOverrides() {
+		return level >= l.level.Level()
+	}
+	var name string
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if key, ok := keyvals[i].(string); ok && key == levelLoggerKey {
+			name = fmt.Sprint(keyvals[i+1])
+		}
+	}
+	return l.level.Enabled(name, level)
+}
+
 func (l *Logger) composeTrace(traceID string) string {
 	traceID = strings.TrimSpace(traceID)
 	if traceID == "" {
 		return ""
 	}
-	if strings.HasPrefix(traceID, "projects/") {
+	projectID := l.projectID()
+	if strings.HasPrefix(traceID, "projects/") || projectID == "" {
 		return traceID
 	}
-	return traceID
+	return "projects/" + projectID + "/traces/" + traceID
+}
+
+// projectID returns the configured project, falling back to one detected after construction.
+func (l *Logger) projectID() string {
+	if l.opts.ProjectID != "" {
+		return l.opts.ProjectID
+	}
+	if p := l.project.Load(); p != nil {
+		return *p
+	}
+	return ""
 }
 
 func (l *Logger) composeLabels(caller string, custom map[string]string) map[string]string {
@@ -459,17 +648,87 @@ func (l *Logger) composeLabels(caller string, custom map[string]string) map[stri
 	return labels
 }
 
+// internalEntry builds a WARNING entry describing a problem inside gclog itself.
+func (l *Logger) internalEntry(msg string) logEntry {
+	entry := logEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Severity:  severityFromLevel(log.LevelWarn),
+		Message:   msg,
+		ServiceContext: serviceContext{
+			Service:     l.opts.Service,
+			Version:     l.opts.Version,
+			Environment: l.opts.Environment,
+		},
+	}
+	if labels := l.composeLabels("", nil); len(labels) > 0 {
+		entry.Labels = labels
+	}
+	return entry
+}
+
 func (l *Logger) write(entry logEntry) error {
-	data, err := json.Marshal(entry)
-	if err != nil {
-		return err
+	if l.insertIDs != nil && entry.InsertID == "" {
+		entry.InsertID = l.insertIDs.next()
+	}
+	if l.otel != nil {
+		l.emitOTel(entry.withStack())
+	}
+	var data []byte
+	if l.format == FormatConsole {
+		data = encodeConsole(entry, l.color)
+	} else {
+		var err error
+		if data, err = json.Marshal(entry.withStack()); err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	}
+	if l.async != nil {
+		if err := l.async.enqueue(levelFromSeverity(entry.Severity), data); err != nil {
+			return err
+		}
+		if entry.Severity == severityFromLevel(log.LevelFatal) {
+			// log.Helper.Fatal 随后会 os.Exit，必须先落盘。
+			return l.async.Flush()
+		}
+		return nil
 	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	_, err = l.w.Write(append(data, '\n'))
+	_, err := l.w.Write(data)
 	return err
 }
 
+// Flush writes out entries buffered by WithAsync; it is a no-op for synchronous loggers.
+func (l *Logger) Flush() error {
+	if l.async == nil {
+		return nil
+	}
+	return l.async.Flush()
+}
+
+// Close stops the sampling summary ticker and writes the pending summary, then flushes
+// and stops the async writer; later entries are written synchronously.
+func (l *Logger) Close() error {
+	if l.summaryStop != nil {
+		l.closeOnce.Do(func() { close(l.summaryStop) })
+		<-l.summaryDone
+	}
+	l.writeSampleSummary()
+	if l.async == nil {
+		return nil
+	}
+	return l.async.Close()
+}
+
+// Dropped returns the number of entries discarded by the async overflow policy.
+func (l *Logger) Dropped() uint64 {
+	if l.async == nil {
+		return 0
+	}
+	return l.async.Dropped()
+}
+
 func formatDurationSeconds(seconds float64) string {
 	if seconds <= 0 {
 		return "0s"
@@ -517,16 +776,34 @@ func severityFromLevel(level log.Level) string {
 
 // logEntry mirrors Cloud Logging JSON structure.
 type logEntry struct {
+	Type           string            `json:"@type,omitempty"`
 	Timestamp      string            `json:"timestamp"`
 	Severity       string            `json:"severity,omitempty"`
 	Message        string            `json:"message,omitempty"`
 	ServiceContext serviceContext    `json:"serviceContext"`
-	Trace          string            `json:"trace,omitempty"`
-	SpanID         string            `json:"spanId,omitempty"`
+	Trace          string            `json:"logging.googleapis.com/trace,omitempty"`
+	SpanID         string            `json:"logging.googleapis.com/spanId,omitempty"`
+	TraceSampled   bool              `json:"logging.googleapis.com/trace_sampled,omitempty"`
 	SourceLocation *sourceLocation   `json:"sourceLocation,omitempty"`
 	HTTPRequest    *httpRequest      `json:"httpRequest,omitempty"`
+	Context        *errorContext     `json:"context,omitempty"`
+	InsertID       string            `json:"logging.googleapis.com/insertId,omitempty"`
+	Operation      *logOperation     `json:"logging.googleapis.com/operation,omitempty"`
 	Labels         map[string]string `json:"labels,omitempty"`
 	JSONPayload    map[string]any    `json:"jsonPayload,omitempty"`
+
+	// stack is the reported error's stack trace; Error Reporting expects it appended to
+	// the message, console output renders it on indented lines after the fields.
+	stack string
+}
+
+// withStack returns entry with the error stack folded into the message, as Error Reporting parses it.
+func (e logEntry) withStack() logEntry {
+	if e.stack != "" {
+		e.Message += "\n\n" + e.stack
+		e.stack = ""
+	}
+	return e
 }
 
 type serviceContext struct {
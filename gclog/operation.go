@@ -0,0 +1,110 @@
+package gclog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// operationKey carries an Operation; "operation" itself is already the Kratos transport label.
+const operationKey = "log_operation"
+
+// Operation groups entries of one long-running unit of work (an outbox batch, an inbox
+// message) in Cloud Logging via logging.googleapis.com/operation.
+type Operation struct {
+	ID       string
+	Producer string
+	last     bool
+	started  *atomic.Bool
+}
+
+type contextOperationKey struct{}
+
+// StartOperation 在 ctx 上开启一个操作；携带该 ctx 的第一条日志标记 first=true。
+func StartOperation(ctx context.Context, id, producer string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextOperationKey{}, &Operation{ID: id, Producer: producer, started: new(atomic.Bool)})
+}
+
+// EndOperation 返回一个 ctx，用它记录的日志标记 last=true；应只用于操作的最后一条日志。
+func EndOperation(ctx context.Context) context.Context {
+	op := OperationFromContext(ctx)
+	if op == nil {
+		return ctx
+	}
+	ended := *op
+	ended.last = true
+	return context.WithValue(ctx, contextOperationKey{}, &ended)
+}
+
+// OperationFromContext 返回 ctx 上的操作，没有时返回 nil。
+func OperationFromContext(ctx context.Context) *Operation {
+	if ctx == nil {
+		return nil
+	}
+	op, _ := ctx.Value(contextOperationKey{}).(*Operation)
+	return op
+}
+
+// WithOperation binds the operation carried by ctx to logger. Loggers from NewComponent
+// read it automatically when logging with that ctx.
+func WithOperation(ctx context.Context, logger log.Logger) log.Logger {
+	op := OperationFromContext(ctx)
+	if op == nil {
+		return logger
+	}
+	return log.With(logger, operationKey, op)
+}
+
+// entry converts op into the LogEntryOperation shape; first is set on the first written entry only.
+func (op *Operation) entry() *logOperation {
+	out := &logOperation{ID: op.ID, Producer: op.Producer, Last: op.last}
+	if op.started != nil {
+		out.First = op.started.CompareAndSwap(false, true)
+	}
+	return out
+}
+
+type logOperation struct {
+	ID       string `json:"id"`
+	Producer string `json:"producer,omitempty"`
+	First    bool   `json:"first,omitempty"`
+	Last     bool   `json:"last,omitempty"`
+}
+
+// DisableInsertID stops gclog from generating logging.googleapis.com/insertId.
+func DisableInsertID() Option {
+	return func(o *Options) {
+		o.DisableInsertID = true
+	}
+}
+
+// insertIDs generates unique insert IDs (a random per-logger prefix plus a counter) so
+// Cloud Logging can de-duplicate entries that are ingested more than once.
+type insertIDs struct {
+	prefix string
+	seq    atomic.Uint64
+}
+
+func insertIDGenerator(disabled bool) *insertIDs {
+	if disabled {
+		return nil
+	}
+	return newInsertIDs()
+}
+
+func newInsertIDs() *insertIDs {
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	return &insertIDs{prefix: hex.EncodeToString(b[:])}
+}
+
+func (g *insertIDs) next() string {
+	return g.prefix + "-" + strconv.FormatUint(g.seq.Add(1), 36)
+}
@@ -0,0 +1,220 @@
+package gclog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// otelScope is the instrumentation scope of records emitted by the bridge.
+const otelScope = "github.com/bionicotaku/lingo-utils/gclog"
+
+// WithLoggerProvider tees every written entry (after validation, redaction and sampling)
+// into an OTel LoggerProvider, e.g. the one installed by observability with an OTLP exporter.
+func WithLoggerProvider(lp otellog.LoggerProvider) Option {
+	return func(o *Options) {
+		o.LoggerProvider = lp
+	}
+}
+
+func newOTelLogger(lp otellog.LoggerProvider) otellog.Logger {
+	if lp == nil {
+		return nil
+	}
+	return lp.Logger(otelScope)
+}
+
+// emitOTel converts entry into a log record. Labels and jsonPayload keys become attributes
+// as-is; httpRequest, sourceLocation, operation and insertId use OTel semantic conventions.
+func (l *Logger) emitOTel(entry logEntry) {
+	var rec otellog.Record
+	if ts, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+		rec.SetTimestamp(ts)
+	}
+	rec.SetSeverity(otelSeverity(entry.Severity))
+	rec.SetSeverityText(entry.Severity)
+	rec.SetBody(otellog.StringValue(entry.Message))
+
+	attrs := make([]otellog.KeyValue, 0, len(entry.Labels)+len(entry.JSONPayload)+8)
+	for k, v := range entry.Labels {
+		attrs = append(attrs, otellog.String(k, v))
+	}
+	for k, v := range entry.JSONPayload {
+		attrs = append(attrs, otellog.KeyValue{Key: k, Value: otelValue(v)})
+	}
+	if entry.InsertID != "" {
+		attrs = append(attrs, otellog.String("log.record.uid", entry.InsertID))
+	}
+	if op := entry.Operation; op != nil {
+		attrs = append(attrs, otellog.String("gclog.operation.id", op.ID))
+		if op.Producer != "" {
+			attrs = append(attrs, otellog.String("gclog.operation.producer", op.Producer))
+		}
+		if op.First {
+			attrs = append(attrs, otellog.Bool("gclog.operation.first", true))
+		}
+		if op.Last {
+			attrs = append(attrs, otellog.Bool("gclog.operation.last", true))
+		}
+	}
+	if src := entry.SourceLocation; src != nil {
+		attrs = append(attrs,
+			otellog.String("code.filepath", src.File),
+			otellog.Int("code.lineno", src.Line),
+		)
+		if src.Function != "" {
+			attrs = append(attrs, otellog.String("code.function", src.Function))
+		}
+	}
+	if req := entry.HTTPRequest; req != nil {
+		attrs = appendHTTPAttributes(attrs, req)
+	}
+	rec.AddAttributes(attrs...)
+
+	l.otel.Emit(otelContext(entry), rec)
+}
+
+// otelContext rebuilds the span context from the entry so the SDK sets trace/span IDs and flags.
+func otelContext(entry logEntry) context.Context {
+	ctx := context.Background()
+	if entry.Trace == "" {
+		return ctx
+	}
+	traceID, err := trace.TraceIDFromHex(entry.Trace[strings.LastIndexByte(entry.Trace, '/')+1:])
+	if err != nil {
+		return ctx
+	}
+	cfg := trace.SpanContextConfig{TraceID: traceID}
+	if spanID, err := trace.SpanIDFromHex(entry.SpanID); err == nil {
+		cfg.SpanID = spanID
+	}
+	if entry.TraceSampled {
+		cfg.TraceFlags = trace.FlagsSampled
+	}
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(cfg))
+}
+
+func appendHTTPAttributes(attrs []otellog.KeyValue, req *httpRequest) []otellog.KeyValue {
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, otellog.String(key, value))
+		}
+	}
+	add("http.request.method", req.RequestMethod)
+	add("url.full", req.RequestURL)
+	add("user_agent.original", req.UserAgent)
+	add("client.address", req.RemoteIP)
+	add("server.address", req.ServerIP)
+	add("network.protocol.name", req.Protocol)
+	add("http.request.header.referer", req.Referer)
+	if req.Status != 0 {
+		attrs = append(attrs, otellog.Int("http.response.status_code", req.Status))
+	}
+	if d, err := time.ParseDuration(req.Latency); err == nil {
+		attrs = append(attrs, otellog.Float64("http.server.request.duration", d.Seconds()))
+	}
+	return attrs
+}
+
+func otelSeverity(severity string) otellog.Severity {
+	switch severity {
+	case "DEBUG":
+		return otellog.SeverityDebug
+	case "WARNING":
+		return otellog.SeverityWarn
+	case "ERROR":
+		return otellog.SeverityError
+	case "CRITICAL":
+		return otellog.SeverityFatal
+	default:
+		return otellog.SeverityInfo
+	}
+}
+
+// otelValue converts payload values; unknown types are JSON encoded like in the stdout entry.
+func otelValue(v any) otellog.Value {
+	switch t := v.(type) {
+	case nil:
+		return otellog.Value{}
+	case string:
+		return otellog.StringValue(t)
+	case bool:
+		return otellog.BoolValue(t)
+	case int:
+		return otellog.IntValue(t)
+	case int8:
+		return otellog.Int64Value(int64(t))
+	case int16:
+		return otellog.Int64Value(int64(t))
+	case int32:
+		return otellog.Int64Value(int64(t))
+	case int64:
+		return otellog.Int64Value(t)
+	case uint8:
+		return otellog.Int64Value(int64(t))
+	case uint16:
+		return otellog.Int64Value(int64(t))
+	case uint32:
+		return otellog.Int64Value(int64(t))
+	case uint:
+		return uintValue(uint64(t))
+	case uint64:
+		return uintValue(t)
+	case float32:
+		return otellog.Float64Value(float64(t))
+	case float64:
+		return otellog.Float64Value(t)
+	case []byte:
+		return otellog.BytesValue(t)
+	case time.Duration:
+		return otellog.StringValue(t.String())
+	case time.Time:
+		return otellog.StringValue(t.Format(time.RFC3339Nano))
+	case error:
+		return otellog.StringValue(t.Error())
+	case map[string]any:
+		kvs := make([]otellog.KeyValue, 0, len(t))
+		for k, e := range t {
+			kvs = append(kvs, otellog.KeyValue{Key: k, Value: otelValue(e)})
+		}
+		return otellog.MapValue(kvs...)
+	case map[string]string:
+		kvs := make([]otellog.KeyValue, 0, len(t))
+		for k, e := range t {
+			kvs = append(kvs, otellog.String(k, e))
+		}
+		return otellog.MapValue(kvs...)
+	case []any:
+		vals := make([]otellog.Value, len(t))
+		for i, e := range t {
+			vals[i] = otelValue(e)
+		}
+		return otellog.SliceValue(vals...)
+	case []string:
+		vals := make([]otellog.Value, len(t))
+		for i, e := range t {
+			vals[i] = otellog.StringValue(e)
+		}
+		return otellog.SliceValue(vals...)
+	case fmt.Stringer:
+		return otellog.StringValue(t.String())
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return otellog.StringValue(fmt.Sprint(v))
+	}
+	return otellog.StringValue(string(data))
+}
+
+func uintValue(v uint64) otellog.Value {
+	if v > math.MaxInt64 {
+		return otellog.StringValue(fmt.Sprint(v))
+	}
+	return otellog.Int64Value(int64(v))
+}
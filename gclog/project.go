@@ -0,0 +1,34 @@
+package gclog
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+)
+
+const (
+	projectEnvVar        = "GOOGLE_CLOUD_PROJECT"
+	projectDetectTimeout = 2 * time.Second
+)
+
+// DetectProjectID resolves the GCP project from $GOOGLE_CLOUD_PROJECT, falling back
+// to the metadata server when running on GCP (Cloud Run, GKE, GCE). It returns ""
+// when neither is available; the metadata lookup is bounded by a short timeout.
+func DetectProjectID(ctx context.Context) string {
+	if id := strings.TrimSpace(os.Getenv(projectEnvVar)); id != "" {
+		return id
+	}
+	ctx, cancel := context.WithTimeout(ctx, projectDetectTimeout)
+	defer cancel()
+	if !metadata.OnGCEWithContext(ctx) {
+		return ""
+	}
+	id, err := metadata.ProjectIDWithContext(ctx)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(id)
+}
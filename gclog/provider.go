@@ -2,25 +2,151 @@ package gclog
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/google/wire"
+	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // Config captures runtime metadata used to annotate structured logs.
 type Config struct {
-	Service              string
-	Version              string
-	Environment          string
+	Service     string
+	Version     string
+	Environment string
+	// ProjectID formats trace fields for Cloud Trace correlation; empty triggers DetectProjectID in the background.
+	ProjectID            string
 	InstanceID           string
 	StaticLabels         map[string]string
 	EnableSourceLocation bool
+	// MinLevel is the global minimum level (debug/info/warn/error); empty keeps DEBUG.
+	MinLevel string
+	// LevelOverrides maps a logger name (the "logger" field, matched by dotted prefix) to its minimum level.
+	LevelOverrides map[string]string
+	// UnknownKeys is fold (default), drop or strict; see UnknownKeyPolicy.
+	UnknownKeys string
+	// Redaction enables secret/PII scrubbing; nil disables it.
+	Redaction *RedactionConfig
+	// DisableErrorReporting keeps ERROR entries out of the Error Reporting format.
+	DisableErrorReporting bool
+	// Async moves writes to a background goroutine; nil keeps synchronous writes.
+	Async *AsyncConfig
+	// DisableInsertID skips the generated logging.googleapis.com/insertId.
+	DisableInsertID bool
+	// OTelLogs tees entries into the global OTel LoggerProvider (installed by observability
+	// when Logs is enabled) for non-GCP backends.
+	OTelLogs bool
+	// Format is auto (default: console on a terminal, JSON otherwise), json or console.
+	Format string
+	// Sampling limits repeated entries per (level, message); nil disables it.
+	Sampling *SamplingConfig
+}
+
+// SamplingConfig configures the Sampler built by NewComponent. Zero values keep the defaults.
+type SamplingConfig struct {
+	// Initial entries per (level, message) kept every Tick (default 100).
+	Initial int
+	// Thereafter keeps one in every Thereafter entries past Initial (default 100).
+	Thereafter int
+	// Tick is the sampling window (default 1s).
+	Tick time.Duration
+	// MaxLevel is the highest sampled level (default info); WARN+ is kept unless raised.
+	MaxLevel string
+	// SummaryInterval controls how often suppressed counts are reported (default 1m).
+	SummaryInterval time.Duration
+}
+
+func (c *SamplingConfig) sampler() (*Sampler, error) {
+	opts := []SamplerOption{SampleTick(c.Tick), SampleSummaryInterval(c.SummaryInterval)}
+	if c.Initial > 0 {
+		opts = append(opts, SampleInitial(c.Initial))
+	}
+	if c.Thereafter > 0 {
+		opts = append(opts, SampleThereafter(c.Thereafter))
+	}
+	if c.MaxLevel != "" {
+		level, err := ParseLevel(c.MaxLevel)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, SampleMaxLevel(level))
+	}
+	return NewSampler(opts...), nil
+}
+
+// AsyncConfig configures the async writer built by NewComponent.
+type AsyncConfig struct {
+	// BufferSize is the number of buffered entries (default 4096).
+	BufferSize int
+	// Overflow is block (default), drop_debug_first or drop_newest; see OverflowPolicy.
+	Overflow string
+	// FlushInterval bounds how long output stays buffered (default 1s).
+	FlushInterval time.Duration
+}
+
+func (c *AsyncConfig) options() ([]AsyncOption, error) {
+	policy, err := ParseOverflowPolicy(c.Overflow)
+	if err != nil {
+		return nil, err
+	}
+	return []AsyncOption{
+		AsyncBufferSize(c.BufferSize),
+		AsyncOverflow(policy),
+		AsyncFlushInterval(c.FlushInterval),
+	}, nil
+}
+
+// RedactionConfig configures the Redactor built by NewComponent.
+type RedactionConfig struct {
+	// Keys overrides DefaultRedactKeys when non-empty.
+	Keys []string
+	// Scrubbers selects built-in scrubbers by name (email, jwt, bearer, card, dsn,
+	// password_param); empty enables all of them.
+	Scrubbers []string
+	// Hash pseudonymises values with HMAC-SHA256(Salt) instead of masking.
+	Hash bool
+	Salt string
+}
+
+func (c *RedactionConfig) redactor() (*Redactor, error) {
+	var opts []RedactorOption
+	if len(c.Keys) > 0 {
+		opts = append(opts, RedactKeys(c.Keys...))
+	}
+	if len(c.Scrubbers) > 0 {
+		scrubbers := make([]*Scrubber, 0, len(c.Scrubbers))
+		for _, name := range c.Scrubbers {
+			sc, ok := ScrubberByName(name)
+			if !ok {
+				return nil, fmt.Errorf("gclog: unknown scrubber %q", name)
+			}
+			scrubbers = append(scrubbers, sc)
+		}
+		opts = append(opts, RedactScrubbers(scrubbers...))
+	}
+	if c.Hash {
+		opts = append(opts, RedactWithHash(c.Salt))
+	}
+	return NewRedactor(opts...), nil
 }
 
 // Component bundles the Kratos-compatible logger.
 type Component struct {
 	Logger log.Logger
+	// Level adjusts filtering at runtime (e.g. via LevelHandler).
+	Level *LevelVar
+
+	base *Logger
+}
+
+// Flush writes out buffered entries when Config.Async is set.
+func (c *Component) Flush() error {
+	if c.base == nil {
+		return nil
+	}
+	return c.base.Flush()
 }
 
 // NewComponent builds a structured logger enriched with trace/span context.
@@ -30,6 +156,9 @@ func NewComponent(cfg Config) (*Component, func(), error) {
 		WithVersion(cfg.Version),
 		WithEnvironment(cfg.Environment),
 	}
+	if cfg.ProjectID != "" {
+		opts = append(opts, WithProjectID(cfg.ProjectID))
+	}
 	labels := map[string]string{}
 	for k, v := range cfg.StaticLabels {
 		labels[k] = v
@@ -43,6 +172,62 @@ func NewComponent(cfg Config) (*Component, func(), error) {
 	if cfg.EnableSourceLocation {
 		opts = append(opts, EnableSourceLocation())
 	}
+	level := NewLevelVar(log.LevelDebug)
+	if cfg.MinLevel != "" {
+		minLevel, err := ParseLevel(cfg.MinLevel)
+		if err != nil {
+			return nil, nil, err
+		}
+		level.SetLevel(minLevel)
+	}
+	overrides, err := parseLevelOverrides(cfg.LevelOverrides)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gclog: %w", err)
+	}
+	for name, l := range overrides {
+		level.SetOverride(name, l)
+	}
+	opts = append(opts, WithLevelVar(level))
+	policy, err := ParseUnknownKeyPolicy(cfg.UnknownKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, WithUnknownKeyPolicy(policy))
+	if cfg.DisableErrorReporting {
+		opts = append(opts, DisableErrorReporting())
+	}
+	if cfg.Redaction != nil {
+		redactor, err := cfg.Redaction.redactor()
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, WithRedactor(redactor))
+	}
+	if cfg.DisableInsertID {
+		opts = append(opts, DisableInsertID())
+	}
+	if cfg.OTelLogs {
+		opts = append(opts, WithLoggerProvider(global.GetLoggerProvider()))
+	}
+	format, err := ParseFormat(cfg.Format)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, WithFormat(format))
+	if cfg.Sampling != nil {
+		sampler, err := cfg.Sampling.sampler()
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, WithSampler(sampler))
+	}
+	if cfg.Async != nil {
+		asyncOpts, err := cfg.Async.options()
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, WithAsync(asyncOpts...))
+	}
 
 	baseLogger, err := NewLogger(opts...)
 	if err != nil {
@@ -65,10 +250,34 @@ func NewComponent(cfg Config) (*Component, func(), error) {
 			}
 			return ""
 		}),
+		sampledKey, log.Valuer(func(ctx context.Context) any {
+			return trace.SpanContextFromContext(ctx).IsSampled()
+		}),
+		labelsKey, log.Valuer(func(ctx context.Context) any {
+			return LabelsFromContext(ctx)
+		}),
+		operationKey, log.Valuer(func(ctx context.Context) any {
+			return OperationFromContext(ctx)
+		}),
 	)
 
-	comp := &Component{Logger: logger}
-	cleanup := func() {}
+	base := baseLogger.(*Logger)
+	comp := &Component{Logger: logger, Level: level, base: base}
+	// Neither Config.ProjectID nor $GOOGLE_CLOUD_PROJECT is set: probe the metadata
+	// server in the background so start-up off GCP does not wait for its timeout.
+	// Entries written before the probe finishes keep the bare trace ID.
+	detectCtx, cancelDetect := context.WithCancel(context.Background())
+	if base.opts.ProjectID == "" {
+		go func() {
+			if id := DetectProjectID(detectCtx); id != "" {
+				base.project.Store(&id)
+			}
+		}()
+	}
+	cleanup := func() {
+		cancelDetect()
+		_ = base.Close()
+	}
 
 	return comp, cleanup, nil
 }
@@ -78,10 +287,15 @@ func ProvideLogger(comp *Component) log.Logger {
 	return comp.Logger
 }
 
+// ProvideLevelVar exposes the runtime level control for admin endpoints.
+func ProvideLevelVar(comp *Component) *LevelVar {
+	return comp.Level
+}
+
 // ProvideHelper exposes a log.Helper built atop the structured logger.
 func ProvideHelper(comp *Component) *log.Helper {
 	return log.NewHelper(comp.Logger)
 }
 
 // ProviderSet wires the logging component for Wire-based injection.
-var ProviderSet = wire.NewSet(NewComponent, ProvideLogger, ProvideHelper)
+var ProviderSet = wire.NewSet(NewComponent, ProvideLogger, ProvideHelper, ProvideLevelVar)
@@ -0,0 +1,32 @@
+package gclog
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Recovery returns a Kratos middleware that turns panics into recovery.ErrUnknownRequest
+// and logs them as Error Reporting events whose stack starts at the panicking frame.
+// Use it in place of recovery.Recovery so panics are grouped in Cloud Error Reporting.
+func Recovery(logger log.Logger) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					perr := newPanicError(r)
+					kvs := []interface{}{log.DefaultMessageKey, perr.Error(), errorKey, perr}
+					if tr, ok := transport.FromServerContext(ctx); ok {
+						kvs = append(kvs, "operation", tr.Operation())
+					}
+					_ = log.WithContext(ctx, logger).Log(log.LevelError, kvs...)
+					err = recovery.ErrUnknownRequest
+				}
+			}()
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package gclog
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces sensitive values in mask mode.
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactKeys lists key names whose values are always redacted. Matching is
+// case-insensitive, treats `_`, `-` and `.` alike and accepts the rule as any run of
+// whole segments, so "db_password" and "X-Api-Key" are covered but "tokenizer" is not.
+var DefaultRedactKeys = []string{
+	"authorization", "password", "passwd", "secret", "token",
+	"api_key", "apikey", "cookie", "set-cookie", "dsn", "credentials",
+}
+
+// Scrubber replaces sensitive substrings inside string values.
+// If Pattern has a capture group named "secret" only that group is replaced.
+type Scrubber struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// hint is a cheap precondition that lets most strings skip the regexp.
+	hint func(string) bool
+	// valid further filters matches (e.g. Luhn check for card numbers).
+	valid func(string) bool
+}
+
+// NewScrubber compiles a custom scrubber.
+func NewScrubber(name, pattern string) (*Scrubber, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("gclog: scrubber %s: %w", name, err)
+	}
+	return &Scrubber{Name: name, Pattern: re}, nil
+}
+
+// Built-in scrubbers.
+var (
+	ScrubEmail = &Scrubber{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+		hint:    func(s string) bool { return strings.IndexByte(s, '@') >= 0 },
+	}
+	ScrubJWT = &Scrubber{
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+		hint:    func(s string) bool { return strings.Contains(s, "eyJ") },
+	}
+	ScrubBearer = &Scrubber{
+		Name:    "bearer",
+		Pattern: regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]+=*)`),
+		hint:    func(s string) bool { return strings.Contains(s, "earer") || strings.Contains(s, "EARER") },
+	}
+	ScrubCardNumber = &Scrubber{
+		Name:    "card",
+		Pattern: regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		hint:    func(s string) bool { return countDigits(s, 13) },
+		valid:   luhnValid,
+	}
+	ScrubDSN = &Scrubber{
+		Name:    "dsn",
+		Pattern: regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.\-]*://[^:/@\s]*:(?P<secret>[^@\s]+)@`),
+		hint:    func(s string) bool { return strings.Contains(s, "://") },
+	}
+	ScrubPasswordParam = &Scrubber{
+		Name:    "password_param",
+		Pattern: regexp.MustCompile(`(?i)\b(?:password|passwd|pwd)=(?P<secret>[^\s&;]+)`),
+		hint:    func(s string) bool { return strings.IndexByte(s, '=') >= 0 },
+	}
+)
+
+// DefaultScrubbers returns the built-in scrubbers in evaluation order.
+func DefaultScrubbers() []*Scrubber {
+	return []*Scrubber{ScrubDSN, ScrubPasswordParam, ScrubBearer, ScrubJWT, ScrubEmail, ScrubCardNumber}
+}
+
+// ScrubberByName resolves a built-in scrubber (email, jwt, bearer, card, dsn, password_param).
+func ScrubberByName(name string) (*Scrubber, bool) {
+	for _, s := range DefaultScrubbers() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// RedactorOption customises a Redactor.
+type RedactorOption func(*Redactor)
+
+// RedactKeys replaces the key rules (DefaultRedactKeys when not set).
+func RedactKeys(keys ...string) RedactorOption {
+	return func(r *Redactor) {
+		r.keys = make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			if k = normalizeKey(strings.TrimSpace(k)); k != "" {
+				r.keys[k] = struct{}{}
+			}
+		}
+	}
+}
+
+// RedactScrubbers replaces the value scrubbers (DefaultScrubbers when not set).
+func RedactScrubbers(scrubbers ...*Scrubber) RedactorOption {
+	return func(r *Redactor) {
+		r.scrubbers = scrubbers
+	}
+}
+
+// RedactWithHash switches to pseudonymisation: values become "sha256:<16 hex>" using
+// HMAC-SHA256 with salt, so equal inputs stay correlatable without exposing them.
+func RedactWithHash(salt string) RedactorOption {
+	return func(r *Redactor) {
+		r.hash = true
+		r.salt = []byte(salt)
+	}
+}
+
+// Redactor removes secrets and PII from log entries. It is immutable and safe for concurrent use.
+type Redactor struct {
+	keys      map[string]struct{}
+	scrubbers []*Scrubber
+	hash      bool
+	salt      []byte
+}
+
+// NewRedactor builds a Redactor with default key rules and scrubbers unless overridden.
+func NewRedactor(opts ...RedactorOption) *Redactor {
+	r := &Redactor{}
+	RedactKeys(DefaultRedactKeys...)(r)
+	r.scrubbers = DefaultScrubbers()
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithRedactor enables redaction of message, error, payload and labels.
+func WithRedactor(r *Redactor) Option {
+	return func(o *Options) {
+		o.Redactor = r
+	}
+}
+
+// SensitiveKey reports whether values stored under key are redacted entirely.
+func (r *Redactor) SensitiveKey(key string) bool {
+	if len(r.keys) == 0 || key == "" {
+		return false
+	}
+	normalized := normalizeKey(key)
+	if _, ok := r.keys[normalized]; ok {
+		return true
+	}
+	for rule := range r.keys {
+		if containsSegments(normalized, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeKey lower-cases key and maps '-' and '.' to '_'.
+func normalizeKey(key string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c == '-' || c == '.':
+			return '_'
+		case c >= 'A' && c <= 'Z':
+			return c + ('a' - 'A')
+		}
+		return c
+	}, key)
+}
+
+// containsSegments reports whether rule occurs in key on '_' boundaries.
+func containsSegments(key, rule string) bool {
+	for offset := 0; ; {
+		idx := strings.Index(key[offset:], rule)
+		if idx < 0 {
+			return false
+		}
+		start, end := offset+idx, offset+idx+len(rule)
+		if (start == 0 || key[start-1] == '_') && (end == len(key) || key[end] == '_') {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// String scrubs sensitive substrings in s.
+func (r *Redactor) String(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, sc := range r.scrubbers {
+		if sc.hint != nil && !sc.hint(s) {
+			continue
+		}
+		s = r.scrub(sc, s)
+	}
+	return s
+}
+
+func (r *Redactor) scrub(sc *Scrubber, s string) string {
+	matches := sc.Pattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+	group := sc.Pattern.SubexpIndex("secret")
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if group > 0 && m[2*group] >= 0 {
+			start, end = m[2*group], m[2*group+1]
+		}
+		if sc.valid != nil && !sc.valid(s[start:end]) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(r.replacement(s[start:end]))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func (r *Redactor) replacement(secret string) string {
+	if !r.hash {
+		return RedactedValue
+	}
+	mac := hmac.New(sha256.New, r.salt)
+	mac.Write([]byte(secret))
+	return "sha256:" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// Value redacts v stored under key, walking maps and slices recursively.
+// Values of other types (structs, numbers) are kept unless the key is sensitive.
+func (r *Redactor) Value(key string, v any) any {
+	if v == nil {
+		return nil
+	}
+	if r.SensitiveKey(key) {
+		if s, ok := v.(string); ok && s == "" {
+			return s
+		}
+		return r.replacement(fmt.Sprint(v))
+	}
+	switch t := v.(type) {
+	case string:
+		return r.String(t)
+	case error:
+		return r.String(t.Error())
+	case map[string]any:
+		return r.Map(t)
+	case map[string]string:
+		out := make(map[string]string, len(t))
+		for k, s := range t {
+			out[k] = r.Value(k, s).(string)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = r.Value("", e)
+		}
+		return out
+	case []string:
+		out := make([]string, len(t))
+		for i, e := range t {
+			out[i] = r.String(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// Map returns a redacted copy of m; the input is never mutated.
+func (r *Redactor) Map(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = r.Value(k, v)
+	}
+	return out
+}
+
+// Labels returns a redacted copy of labels.
+func (r *Redactor) Labels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return labels
+	}
+	return r.Value("", labels).(map[string]string)
+}
+
+func countDigits(s string, min int) bool {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			if n++; n >= min {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// luhnValid filters card-like digit runs so IDs and timestamps are left alone.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
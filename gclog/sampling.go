@@ -0,0 +1,226 @@
+package gclog
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	samplerCounters = 4096
+	// maxSummaryMessages bounds the per-message breakdown in summary entries.
+	maxSummaryMessages = 50
+	otherMessages      = "(other)"
+)
+
+// SamplerOption customises a Sampler.
+type SamplerOption func(*Sampler)
+
+// SampleInitial sets how many entries per (level, message) are kept each tick (default 100).
+func SampleInitial(n int) SamplerOption {
+	return func(s *Sampler) {
+		if n >= 0 {
+			s.initial = uint64(n)
+		}
+	}
+}
+
+// SampleThereafter keeps one in every m entries after the initial quota (default 100);
+// 0 drops everything past the quota.
+func SampleThereafter(m int) SamplerOption {
+	return func(s *Sampler) {
+		if m >= 0 {
+			s.thereafter = uint64(m)
+		}
+	}
+}
+
+// SampleTick sets the window the quota applies to (default 1s).
+func SampleTick(d time.Duration) SamplerOption {
+	return func(s *Sampler) {
+		if d > 0 {
+			s.tick = d
+		}
+	}
+}
+
+// SampleMaxLevel sets the highest sampled level (default INFO); entries above it are always kept.
+func SampleMaxLevel(level log.Level) SamplerOption {
+	return func(s *Sampler) {
+		s.maxLevel = level
+	}
+}
+
+// SampleSummaryInterval sets how often a summary of suppressed entries is written (default 1m).
+func SampleSummaryInterval(d time.Duration) SamplerOption {
+	return func(s *Sampler) {
+		if d > 0 {
+			s.summaryEvery = d
+		}
+	}
+}
+
+// Sampler limits repeated entries: the first N per tick per (level, message) pass, then
+// one in M. Counters live in a fixed hash table, so memory does not grow with the number
+// of distinct messages; colliding messages share a quota.
+type Sampler struct {
+	initial      uint64
+	thereafter   uint64
+	tick         time.Duration
+	maxLevel     log.Level
+	summaryEvery time.Duration
+
+	counters [samplerCounters]sampleCounter
+
+	mu         sync.Mutex
+	suppressed map[string]uint64
+	total      uint64
+}
+
+type sampleCounter struct {
+	resetAt atomic.Int64
+	n       atomic.Uint64
+}
+
+// NewSampler builds a Sampler; see the Sample* options for defaults.
+func NewSampler(opts ...SamplerOption) *Sampler {
+	s := &Sampler{
+		initial:      100,
+		thereafter:   100,
+		tick:         time.Second,
+		maxLevel:     log.LevelInfo,
+		summaryEvery: time.Minute,
+		suppressed:   make(map[string]uint64),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithSampler samples entries at or below the sampler's max level before they are written.
+func WithSampler(s *Sampler) Option {
+	return func(o *Options) {
+		o.Sampler = s
+	}
+}
+
+// Allow reports whether an entry should be written, recording it as suppressed otherwise.
+func (s *Sampler) Allow(level log.Level, msg string, now time.Time) bool {
+	if level > s.maxLevel {
+		return true
+	}
+	n := s.counter(level, msg).inc(now.UnixNano(), int64(s.tick))
+	if n <= s.initial || (s.thereafter > 0 && (n-s.initial)%s.thereafter == 0) {
+		return true
+	}
+	s.mu.Lock()
+	key := msg
+	if _, ok := s.suppressed[key]; !ok && len(s.suppressed) >= maxSummaryMessages {
+		key = otherMessages
+	}
+	s.suppressed[key]++
+	s.total++
+	s.mu.Unlock()
+	return false
+}
+
+func (s *Sampler) counter(level log.Level, msg string) *sampleCounter {
+	// FNV-1a，内联以避免热路径上的分配。
+	h := uint32(2166136261)
+	h = (h ^ uint32(uint8(level))) * 16777619
+	for i := 0; i < len(msg); i++ {
+		h = (h ^ uint32(msg[i])) * 16777619
+	}
+	return &s.counters[h%samplerCounters]
+}
+
+// counterResetting marks a counter whose window is being reset by another goroutine.
+const counterResetting = -1
+
+func (c *sampleCounter) inc(now, tick int64) uint64 {
+	for {
+		resetAt := c.resetAt.Load()
+		if resetAt == counterResetting {
+			runtime.Gosched()
+			continue
+		}
+		if resetAt > now {
+			return c.n.Add(1)
+		}
+		// 只有 CAS 成功的 goroutine 重置计数；重置期间其他调用方等待，避免计数被覆盖。
+		if c.resetAt.CompareAndSwap(resetAt, counterResetting) {
+			c.n.Store(1)
+			c.resetAt.Store(now + tick)
+			return 1
+		}
+	}
+}
+
+// takeSuppressed returns and resets the suppressed counts.
+func (s *Sampler) takeSuppressed() (uint64, map[string]uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.total == 0 {
+		return 0, nil
+	}
+	total, counts := s.total, s.suppressed
+	s.total, s.suppressed = 0, make(map[string]uint64)
+	return total, counts
+}
+
+// sample applies the sampler to an entry.
+func (l *Logger) sample(level log.Level, keyvals []interface{}) bool {
+	s := l.opts.Sampler
+	if level > s.maxLevel {
+		return true
+	}
+	var msg string
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if key, ok := keyvals[i].(string); ok && key == log.DefaultMessageKey {
+			msg, _ = keyvals[i+1].(string)
+			break
+		}
+	}
+	return s.Allow(level, msg, time.Now())
+}
+
+// runSampleSummary writes a summary every SampleSummaryInterval until stop is closed.
+func (l *Logger) runSampleSummary(every time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			l.writeSampleSummary()
+		}
+	}
+}
+
+// writeSampleSummary emits one WARNING entry listing messages suppressed since the last summary.
+func (l *Logger) writeSampleSummary() {
+	if l.opts.Sampler == nil {
+		return
+	}
+	total, counts := l.opts.Sampler.takeSuppressed()
+	if total == 0 {
+		return
+	}
+	suppressed := make(map[string]any, len(counts))
+	for msg, n := range counts {
+		if l.opts.Redactor != nil {
+			msg = l.opts.Redactor.String(msg)
+		}
+		suppressed[msg] = n
+	}
+	entry := l.internalEntry(fmt.Sprintf("gclog: sampling suppressed %d log entries", total))
+	entry.JSONPayload = map[string]any{"suppressed": suppressed, "suppressed_total": total}
+	_ = l.write(entry)
+}
@@ -0,0 +1,211 @@
+package gclog_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	gclog "github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type grpcTransport struct {
+	operation string
+	header    headerCarrier
+}
+
+type headerCarrier metadata.MD
+
+func (h headerCarrier) Get(key string) string {
+	if v := metadata.MD(h).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+func (h headerCarrier) Set(key, value string)      { metadata.MD(h).Set(key, value) }
+func (h headerCarrier) Add(key, value string)      { metadata.MD(h).Append(key, value) }
+func (h headerCarrier) Keys() []string             { return nil }
+func (h headerCarrier) Values(key string) []string { return metadata.MD(h).Get(key) }
+
+func (t *grpcTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (t *grpcTransport) Endpoint() string                { return "grpc://127.0.0.1:9000" }
+func (t *grpcTransport) Operation() string               { return t.operation }
+func (t *grpcTransport) RequestHeader() transport.Header { return t.header }
+func (t *grpcTransport) ReplyHeader() transport.Header   { return headerCarrier(metadata.MD{}) }
+
+func grpcContext(operation string) context.Context {
+	ctx := transport.NewServerContext(context.Background(), &grpcTransport{
+		operation: operation,
+		header:    headerCarrier(metadata.Pairs("user-agent", "grpc-go/1.76.0")),
+	})
+	return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234}})
+}
+
+func TestAccessLogGRPC(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	handler := gclog.AccessLog(logger, gclog.AccessLogBodies(8))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String("a long reply value"), nil
+	})
+	ctx := gclog.StubTraceContext(grpcContext("/catalog.v1.Catalog/Get"), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	_, err = handler(ctx, wrapperspb.String("hi"))
+	require.NoError(t, err)
+
+	entry := decodeEntry(t, buf.String())
+	require.Equal(t, "INFO", entry["severity"])
+	require.Equal(t, "POST /catalog.v1.Catalog/Get 200", entry["message"])
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["logging.googleapis.com/trace"])
+
+	req := entry["httpRequest"].(map[string]any)
+	require.Equal(t, "POST", req["requestMethod"])
+	require.Equal(t, "/catalog.v1.Catalog/Get", req["requestUrl"])
+	require.Equal(t, float64(200), req["status"])
+	require.Equal(t, "10.0.0.7", req["remoteIp"])
+	require.Equal(t, "grpc-go/1.76.0", req["userAgent"])
+	require.Equal(t, "HTTP/2", req["protocol"])
+	require.Equal(t, "4", req["requestSize"])
+	require.NotEmpty(t, req["responseSize"])
+	require.Regexp(t, `^\d+\.\d{3}s$`, req["latency"])
+
+	payload := entry["jsonPayload"].(map[string]any)["payload"].(map[string]any)
+	require.Equal(t, "/catalog.v1.Catalog/Get", payload["operation"])
+	require.Equal(t, "OK", payload["grpc_code"])
+	require.Equal(t, `"hi"`, payload["request_body"])
+	require.Equal(t, `"a long ...(truncated)`, payload["response_body"])
+}
+
+func TestAccessLogTruncatesOnRuneBoundary(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	// `"中文回复"` is 14 bytes; a 5-byte limit falls inside the second character.
+	handler := gclog.AccessLog(logger, gclog.AccessLogBodies(5))(func(context.Context, interface{}) (interface{}, error) {
+		return wrapperspb.String("中文回复"), nil
+	})
+	_, err = handler(grpcContext("/catalog.v1.Catalog/Get"), wrapperspb.String("hi"))
+	require.NoError(t, err)
+
+	payload := decodeEntry(t, buf.String())["jsonPayload"].(map[string]any)["payload"].(map[string]any)
+	body := payload["response_body"].(string)
+	require.True(t, utf8.ValidString(body))
+	require.Equal(t, `"中...(truncated)`, body)
+}
+
+func TestAccessLogMapsKratosErrors(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	handler := gclog.AccessLog(logger)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.NotFound("VIDEO_NOT_FOUND", "video missing")
+	})
+	_, err = handler(grpcContext("/catalog.v1.Catalog/Get"), nil)
+	require.Error(t, err)
+
+	entry := decodeEntry(t, buf.String())
+	require.Equal(t, "WARNING", entry["severity"])
+	require.Equal(t, float64(404), entry["httpRequest"].(map[string]any)["status"])
+	require.NotContains(t, entry["httpRequest"], "responseSize")
+	payload := entry["jsonPayload"].(map[string]any)["payload"].(map[string]any)
+	require.Equal(t, "VIDEO_NOT_FOUND", payload["reason"])
+	require.Equal(t, "NotFound", payload["grpc_code"])
+	require.Contains(t, payload["error"], "video missing")
+
+	buf.Reset()
+	handler = gclog.AccessLog(logger)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.New(503, "UNAVAILABLE", "db down")
+	})
+	_, _ = handler(grpcContext("/catalog.v1.Catalog/Get"), nil)
+	entry = decodeEntry(t, buf.String())
+	require.Equal(t, "ERROR", entry["severity"])
+	require.NotContains(t, entry, "@type")
+}
+
+func TestAccessLogPanicInsideRecovery(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+	recoveryLogger, _, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	handler := middleware.Chain(gclog.Recovery(recoveryLogger), gclog.AccessLog(logger))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	_, err = handler(grpcContext("/catalog.v1.Catalog/Get"), nil)
+	require.Error(t, err)
+	require.Equal(t, 500, int(errors.FromError(err).Code))
+
+	entry := decodeEntry(t, buf.String())
+	require.Equal(t, "ERROR", entry["severity"])
+	require.Equal(t, "POST /catalog.v1.Catalog/Get 500", entry["message"])
+	payload := entry["jsonPayload"].(map[string]any)["payload"].(map[string]any)
+	require.Equal(t, "Internal", payload["grpc_code"])
+	require.Contains(t, payload["error"], "panic: boom")
+}
+
+func TestAccessLogSkip(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	mw := gclog.AccessLog(logger,
+		gclog.AccessLogSkip("/grpc.health.v1.Health/*"),
+		gclog.AccessLogSkipFunc(func(_ context.Context, operation string) bool {
+			return strings.HasSuffix(operation, "/Ping")
+		}),
+	)
+	handler := mw(func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+	for _, op := range []string{"/grpc.health.v1.Health/Check", "/catalog.v1.Catalog/Ping"} {
+		_, err := handler(grpcContext(op), nil)
+		require.NoError(t, err)
+	}
+	require.Empty(t, buf.String())
+
+	_, err = handler(grpcContext("/catalog.v1.Catalog/Get"), nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, buf.String())
+}
+
+func TestAccessLogHTTP(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	srv := khttp.NewServer(khttp.Middleware(gclog.AccessLog(logger, gclog.AccessLogSkip("/healthz"))))
+	route := srv.Route("/")
+	serve := func(ctx khttp.Context) error {
+		h := ctx.Middleware(func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+		if _, err := h(ctx, nil); err != nil {
+			return err
+		}
+		return ctx.String(http.StatusOK, "ok")
+	}
+	route.GET("/v1/videos", serve)
+	route.GET("/healthz", serve)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/videos?page=2", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.1")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := decodeEntry(t, buf.String())
+	require.Equal(t, "GET /v1/videos 200", entry["message"], "query string stays out of the message")
+	httpReq := entry["httpRequest"].(map[string]any)
+	require.Equal(t, "GET", httpReq["requestMethod"])
+	require.Equal(t, "/v1/videos?page=2", httpReq["requestUrl"])
+	require.Equal(t, "curl/8.0", httpReq["userAgent"])
+	require.Equal(t, "203.0.113.9", httpReq["remoteIp"])
+	require.Equal(t, "HTTP/1.1", httpReq["protocol"])
+
+	buf.Reset()
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Empty(t, buf.String())
+}
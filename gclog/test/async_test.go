@@ -0,0 +1,212 @@
+package gclog_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gclog "github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// gateWriter blocks the first Write until released so tests can fill the async buffer.
+type gateWriter struct {
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() {
+		close(w.entered)
+		<-w.release
+	})
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) messages(t *testing.T) []string {
+	t.Helper()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var out []string
+	for _, line := range strings.Split(strings.TrimSpace(w.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		out = append(out, decodeEntry(t, line)["message"].(string))
+	}
+	return out
+}
+
+// stallAsyncLogger returns an async logger whose background writer is stuck in the gate
+// after writing "first", leaving the buffer empty.
+func stallAsyncLogger(t *testing.T, policy gclog.OverflowPolicy, opts ...gclog.Option) (*gclog.Logger, *gateWriter) {
+	t.Helper()
+	w := newGateWriter()
+	options := append([]gclog.Option{
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithWriter(w),
+		gclog.WithAsync(gclog.AsyncBufferSize(2), gclog.AsyncOverflow(policy), gclog.AsyncFlushInterval(time.Hour)),
+	}, opts...)
+	logger, err := gclog.NewLogger(options...)
+	require.NoError(t, err)
+	l := logger.(*gclog.Logger)
+
+	require.NoError(t, l.Log(log.LevelInfo, log.DefaultMessageKey, "first"))
+	go func() { _ = l.Flush() }()
+	select {
+	case <-w.entered:
+	case <-time.After(time.Second):
+		t.Fatal("async writer did not reach the underlying writer")
+	}
+	return l, w
+}
+
+func TestAsyncLoggerFlushAndClose(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := gclog.NewLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithWriter(&buf),
+		gclog.WithAsync(gclog.AsyncFlushInterval(time.Hour)),
+	)
+	require.NoError(t, err)
+	l := logger.(*gclog.Logger)
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, l.Log(log.LevelInfo, log.DefaultMessageKey, "hello"))
+	}
+	require.NoError(t, l.Flush())
+	require.Equal(t, 100, strings.Count(buf.String(), "\n"))
+
+	require.NoError(t, l.Log(log.LevelInfo, log.DefaultMessageKey, "pending"))
+	require.NoError(t, l.Close())
+	require.Equal(t, 101, strings.Count(buf.String(), "\n"))
+
+	// 关闭后同步写出。
+	require.NoError(t, l.Log(log.LevelInfo, log.DefaultMessageKey, "after close"))
+	require.Contains(t, buf.String(), "after close")
+	require.NoError(t, l.Close())
+}
+
+func TestAsyncLoggerPeriodicFlush(t *testing.T) {
+	w := newGateWriter()
+	close(w.release)
+	logger, err := gclog.NewLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithWriter(w),
+		gclog.WithAsync(gclog.AsyncFlushInterval(10*time.Millisecond)),
+	)
+	require.NoError(t, err)
+	defer logger.(*gclog.Logger).Close()
+
+	require.NoError(t, logger.Log(log.LevelInfo, log.DefaultMessageKey, "tick"))
+	require.Eventually(t, func() bool {
+		return len(w.messages(t)) == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestAsyncOverflowDropDebugFirst(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	l, w := stallAsyncLogger(t, gclog.OverflowDropDebugFirst, gclog.WithMeter(meter))
+
+	require.NoError(t, l.Log(log.LevelDebug, log.DefaultMessageKey, "debug-1"))
+	require.NoError(t, l.Log(log.LevelInfo, log.DefaultMessageKey, "info-1"))
+	require.NoError(t, l.Log(log.LevelInfo, log.DefaultMessageKey, "info-2"))   // evicts debug-1
+	require.NoError(t, l.Log(log.LevelDebug, log.DefaultMessageKey, "debug-2")) // dropped
+	require.NoError(t, l.Log(log.LevelWarn, log.DefaultMessageKey, "warn-1"))   // no DEBUG left: dropped
+	require.Equal(t, uint64(3), l.Dropped())
+
+	close(w.release)
+	require.NoError(t, l.Close())
+	require.Equal(t, []string{"first", "info-1", "info-2"}, w.messages(t))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "gclog_async_dropped_total" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				total += dp.Value
+			}
+		}
+	}
+	require.Equal(t, int64(3), total)
+}
+
+func TestAsyncOverflowDropNewest(t *testing.T) {
+	l, w := stallAsyncLogger(t, gclog.OverflowDropNewest)
+
+	require.NoError(t, l.Log(log.LevelDebug, log.DefaultMessageKey, "a"))
+	require.NoError(t, l.Log(log.LevelInfo, log.DefaultMessageKey, "b"))
+	require.NoError(t, l.Log(log.LevelError, log.DefaultMessageKey, "c"))
+	require.Equal(t, uint64(1), l.Dropped())
+
+	close(w.release)
+	require.NoError(t, l.Close())
+	require.Equal(t, []string{"first", "a", "b"}, w.messages(t))
+}
+
+func TestAsyncOverflowBlock(t *testing.T) {
+	l, w := stallAsyncLogger(t, gclog.OverflowBlock)
+
+	require.NoError(t, l.Log(log.LevelInfo, log.DefaultMessageKey, "a"))
+	require.NoError(t, l.Log(log.LevelInfo, log.DefaultMessageKey, "b"))
+	done := make(chan struct{})
+	go func() {
+		_ = l.Log(log.LevelInfo, log.DefaultMessageKey, "c")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Log returned while the buffer was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(w.release)
+	<-done
+	require.NoError(t, l.Close())
+	require.Equal(t, []string{"first", "a", "b", "c"}, w.messages(t))
+	require.Zero(t, l.Dropped())
+}
+
+func TestComponentCleanupFlushesAsync(t *testing.T) {
+	comp, cleanup, err := gclog.NewComponent(gclog.Config{
+		Service:   "svc",
+		Version:   "v1",
+		ProjectID: "proj",
+		Async:     &gclog.AsyncConfig{Overflow: "drop_newest", FlushInterval: time.Hour},
+	})
+	require.NoError(t, err)
+	require.NoError(t, comp.Logger.Log(log.LevelInfo, log.DefaultMessageKey, "bye"))
+	require.NoError(t, comp.Flush())
+	cleanup()
+
+	_, _, err = gclog.NewComponent(gclog.Config{
+		Service:   "svc",
+		Version:   "v1",
+		ProjectID: "proj",
+		Async:     &gclog.AsyncConfig{Overflow: "bogus"},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,125 @@
+package gclog_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	gclog "github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleFormatLine(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.DisableInstanceID(),
+		gclog.WithProjectID("proj"),
+		gclog.WithFormat(gclog.FormatConsole),
+		gclog.WithAllowedKeys("event_id"),
+	)
+	require.NoError(t, err)
+
+	ctx := gclog.StubTraceContext(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	traced := gclog.WithTrace(ctx, logger)
+	traced = gclog.WithCaller(traced, "outbox/publisher.go:88")
+	traced = gclog.WithLabels(traced, map[string]string{"team": "core"})
+	require.NoError(t, log.WithContext(ctx, traced).Log(log.LevelWarn,
+		log.DefaultMessageKey, "publish retry",
+		"event_id", "evt 1",
+		"attempt", 3,
+	))
+
+	line := buf.String()
+	require.True(t, strings.HasSuffix(line, "\n"))
+	require.Equal(t, 1, strings.Count(line, "\n"))
+	require.NotContains(t, line, "\x1b[")
+	require.Regexp(t, `^\d{2}:\d{2}:\d{2}\.\d{3} WARN  publish retry `, line)
+	require.Contains(t, line, " caller=outbox/publisher.go:88")
+	require.Contains(t, line, " team=core")
+	require.Contains(t, line, ` event_id="evt 1"`)
+	require.Contains(t, line, " attempt=3")
+	require.Contains(t, line, " trace=4bf92f3577b34da6a3ce929d0e0e4736")
+	require.Less(t, strings.Index(line, "attempt="), strings.Index(line, "event_id="))
+	require.False(t, json.Valid([]byte(line)))
+}
+
+func TestConsoleFormatRendersErrorStackAfterFields(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithFormat(gclog.FormatConsole),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, logger.Log(log.LevelError,
+		log.DefaultMessageKey, "charge failed",
+		"error", gclog.WithStack(errors.New("card declined")),
+	))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Greater(t, len(lines), 1, "stack should follow the entry line")
+	require.Regexp(t, `^\d{2}:\d{2}:\d{2}\.\d{3} ERROR charge failed: card declined `, lines[0])
+	require.Contains(t, lines[0], "error=")
+	for _, line := range lines[1:] {
+		require.True(t, strings.HasPrefix(line, "    "), "stack line not indented: %q", line)
+	}
+}
+
+func TestConsoleFormatKeepsValidationAndRedaction(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithFormat(gclog.FormatConsole),
+		gclog.WithRedactor(gclog.NewRedactor()),
+		gclog.WithUnknownKeyPolicy(gclog.UnknownKeyDrop),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, logger.Log(log.LevelInfo,
+		log.DefaultMessageKey, "login alice@example.com",
+		"password", "hunter2",
+	))
+	out := buf.String()
+	require.Contains(t, out, "login "+gclog.RedactedValue)
+	require.NotContains(t, out, "hunter2")
+	require.NotContains(t, out, "alice@example.com")
+	require.Contains(t, out, "WARN  gclog: dropped unsupported log field")
+
+	strict, _, err := gclog.NewTestLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithFormat(gclog.FormatConsole),
+		gclog.WithUnknownKeyPolicy(gclog.UnknownKeyStrict),
+	)
+	require.NoError(t, err)
+	require.Error(t, strict.Log(log.LevelInfo, "foo", "bar"))
+}
+
+func TestAutoFormatUsesJSONForNonTerminal(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+	require.NoError(t, logger.Log(log.LevelInfo, log.DefaultMessageKey, "hello"))
+	require.Equal(t, "hello", decodeEntry(t, buf.String())["message"])
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]gclog.Format{
+		"":        gclog.FormatAuto,
+		"auto":    gclog.FormatAuto,
+		"json":    gclog.FormatJSON,
+		"console": gclog.FormatConsole,
+	} {
+		got, err := gclog.ParseFormat(in)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+		if in != "" {
+			require.Equal(t, in, got.String())
+		}
+	}
+	_, err := gclog.ParseFormat("text")
+	require.Error(t, err)
+}
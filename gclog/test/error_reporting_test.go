@@ -0,0 +1,92 @@
+package gclog_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	gclog "github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/stretchr/testify/require"
+)
+
+const reportedErrorEvent = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+
+func loadVideo() error {
+	return gclog.WithStack(errors.New("video not found"))
+}
+
+func TestErrorEntriesUseErrorReportingFormat(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("catalog"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	wrapped := fmt.Errorf("load: %w", loadVideo())
+	require.NoError(t, gclog.WithError(logger, wrapped).Log(log.LevelError, log.DefaultMessageKey, "request failed"))
+
+	entry := decodeEntry(t, buf.String())
+	require.Equal(t, reportedErrorEvent, entry["@type"])
+	require.Equal(t, "catalog", entry["serviceContext"].(map[string]any)["service"])
+	msg := entry["message"].(string)
+	require.True(t, strings.HasPrefix(msg, "request failed: load: video not found\n\ngoroutine 1 [running]:\n"), msg)
+	require.Contains(t, msg, "gclog/test_test.loadVideo(...)")
+
+	loc := entry["context"].(map[string]any)["reportLocation"].(map[string]any)
+	require.Equal(t, "github.com/bionicotaku/lingo-utils/gclog/test_test.loadVideo", loc["functionName"])
+	require.True(t, strings.HasSuffix(loc["filePath"].(string), "error_reporting_test.go"))
+	require.Equal(t, "load: video not found", entry["jsonPayload"].(map[string]any)["error"])
+}
+
+func TestErrorWithoutStackReportsLogSite(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("catalog"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	log.NewHelper(gclog.WithError(logger, errors.New("boom"))).Error("failed")
+
+	entry := decodeEntry(t, buf.String())
+	loc := entry["context"].(map[string]any)["reportLocation"].(map[string]any)
+	require.Equal(t, "github.com/bionicotaku/lingo-utils/gclog/test_test.TestErrorWithoutStackReportsLogSite", loc["functionName"])
+}
+
+func TestErrorReportingOnlyForErrorsAtErrorLevel(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("catalog"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+	require.NoError(t, gclog.WithError(logger, errors.New("retrying")).Log(log.LevelWarn, log.DefaultMessageKey, "transient"))
+	require.NoError(t, logger.Log(log.LevelError, log.DefaultMessageKey, "no error value"))
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := decodeEntry(t, line)
+		require.NotContains(t, entry, "@type")
+		require.NotContains(t, entry["message"], "goroutine")
+	}
+
+	logger, buf, err = gclog.NewTestLogger(gclog.WithService("catalog"), gclog.WithVersion("v1"), gclog.DisableErrorReporting())
+	require.NoError(t, err)
+	require.NoError(t, gclog.WithError(logger, errors.New("boom")).Log(log.LevelError, log.DefaultMessageKey, "failed"))
+	require.Equal(t, "failed", decodeEntry(t, buf.String())["message"])
+}
+
+func explode() {
+	panic("nil map write")
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("catalog"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	handler := gclog.Recovery(logger)(func(context.Context, interface{}) (interface{}, error) {
+		explode()
+		return nil, nil
+	})
+	_, err = handler(context.Background(), "req")
+	require.ErrorIs(t, err, recovery.ErrUnknownRequest)
+
+	entry := decodeEntry(t, buf.String())
+	require.Equal(t, "ERROR", entry["severity"])
+	require.Equal(t, reportedErrorEvent, entry["@type"])
+	require.True(t, strings.HasPrefix(entry["message"].(string), "panic: nil map write\n\ngoroutine 1 [running]:\n"))
+	loc := entry["context"].(map[string]any)["reportLocation"].(map[string]any)
+	require.Equal(t, "github.com/bionicotaku/lingo-utils/gclog/test_test.explode", loc["functionName"])
+}
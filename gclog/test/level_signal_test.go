@@ -0,0 +1,25 @@
+//go:build unix
+
+package gclog_test
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	gclog "github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToggleDebugOnSignal(t *testing.T) {
+	level := gclog.NewLevelVar(log.LevelWarn)
+	stop := gclog.ToggleDebugOnSignal(level, syscall.SIGUSR1)
+	t.Cleanup(stop)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, func() bool { return level.Level() == log.LevelDebug }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, func() bool { return level.Level() == log.LevelWarn }, time.Second, 10*time.Millisecond)
+}
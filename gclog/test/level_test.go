@@ -0,0 +1,137 @@
+package gclog_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gclog "github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func countLines(raw string) int {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0
+	}
+	return len(strings.Split(raw, "\n"))
+}
+
+func TestMinLevelFiltersEntries(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"), gclog.WithMinLevel(log.LevelInfo))
+	require.NoError(t, err)
+
+	helper := log.NewHelper(logger)
+	helper.Debug("outbox idle")
+	require.Zero(t, countLines(buf.String()))
+
+	helper.Info("published")
+	require.Equal(t, "published", decodeEntry(t, buf.String())["message"])
+}
+
+func TestDefaultLevelWritesDebug(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	log.NewHelper(logger).Debug("verbose")
+	require.Equal(t, "DEBUG", decodeEntry(t, buf.String())["severity"])
+}
+
+func TestLevelOverridesByLogger(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithMinLevel(log.LevelInfo),
+		gclog.WithLevelOverride("gcpubsub", log.LevelWarn),
+		gclog.WithLevelOverride("gcjwt.jwks", log.LevelDebug),
+	)
+	require.NoError(t, err)
+
+	log.NewHelper(log.With(logger, "logger", "gcpubsub.subscriber")).Info("pulled")
+	require.Zero(t, countLines(buf.String()), "child loggers inherit the override")
+	log.NewHelper(log.With(logger, "logger", "gcpubsub")).Warn("slow ack")
+	require.Equal(t, 1, countLines(buf.String()))
+
+	log.NewHelper(log.With(logger, "logger", "gcjwt.jwks")).Debug("refreshed")
+	require.Equal(t, 2, countLines(buf.String()), "overrides may lower the level")
+	log.NewHelper(log.With(logger, "logger", "gcjwt.oidc")).Debug("discovery")
+	require.Equal(t, 2, countLines(buf.String()), "siblings fall back to the global level")
+
+	// Kratos logging middleware sets component=grpc; it must not match logger overrides.
+	log.NewHelper(log.With(logger, "component", "gcpubsub")).Info("access")
+	require.Equal(t, 3, countLines(buf.String()), "component is not an override key")
+}
+
+func TestLevelVarRuntimeChange(t *testing.T) {
+	level := gclog.NewLevelVar(log.LevelWarn)
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"), gclog.WithLevelVar(level))
+	require.NoError(t, err)
+	helper := log.NewHelper(log.With(logger, "logger", "publisher"))
+
+	helper.Debug("idle")
+	require.Zero(t, countLines(buf.String()))
+
+	level.SetOverride("publisher", log.LevelDebug)
+	helper.Debug("idle")
+	require.Equal(t, 1, countLines(buf.String()))
+
+	level.RemoveOverride("publisher")
+	helper.Debug("idle")
+	require.Equal(t, 1, countLines(buf.String()))
+}
+
+func TestLevelHandler(t *testing.T) {
+	level := gclog.NewLevelVar(log.LevelInfo)
+	srv := httptest.NewServer(gclog.LevelHandler(level))
+	t.Cleanup(srv.Close)
+
+	do := func(method, query string) (int, map[string]any) {
+		req, err := http.NewRequest(method, srv.URL+"?"+query, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var state map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&state)
+		return resp.StatusCode, state
+	}
+
+	status, state := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "INFO", state["level"])
+
+	status, state = do(http.MethodPut, "logger=gcpubsub&level=debug")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "DEBUG", state["overrides"].(map[string]any)["gcpubsub"])
+	require.True(t, level.Enabled("gcpubsub", log.LevelDebug))
+
+	status, _ = do(http.MethodPost, "level=warning")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, log.LevelWarn, level.Level())
+
+	status, state = do(http.MethodDelete, "logger=gcpubsub")
+	require.Equal(t, http.StatusOK, status)
+	require.Nil(t, state["overrides"])
+
+	status, _ = do(http.MethodPut, "level=verbose")
+	require.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestComponentLevelConfig(t *testing.T) {
+	comp, cleanup, err := gclog.NewComponent(gclog.Config{
+		Service:        "svc",
+		Version:        "v1",
+		MinLevel:       "warn",
+		LevelOverrides: map[string]string{"publisher": "debug"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+	require.Equal(t, log.LevelWarn, comp.Level.Level())
+	require.True(t, comp.Level.Enabled("publisher", log.LevelDebug))
+
+	_, _, err = gclog.NewComponent(gclog.Config{Service: "svc", Version: "v1", MinLevel: "loud"})
+	require.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
@@ -14,6 +15,7 @@ import (
 	gclog "github.com/bionicotaku/lingo-utils/gclog"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestNewLoggerValidation(t *testing.T) {
@@ -71,6 +73,7 @@ func TestPayloadTypeError(t *testing.T) {
 }
 
 func TestWithTraceAndHelper(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
 	logger, buf, err := gclog.NewTestLogger(
 		gclog.WithService("svc"),
 		gclog.WithVersion("v1"),
@@ -82,12 +85,76 @@ func TestWithTraceAndHelper(t *testing.T) {
 	helper.InfoWithPayload("done", map[string]any{"status": "ok"})
 
 	entry := decodeEntry(t, buf.String())
-	require.Equal(t, "0000000000000000000000001234abcd", entry["trace"])
+	require.Equal(t, "0000000000000000000000001234abcd", entry["logging.googleapis.com/trace"])
+	require.Equal(t, "0000000000000011", entry["logging.googleapis.com/spanId"])
+	require.Equal(t, true, entry["logging.googleapis.com/trace_sampled"])
 	require.Equal(t, "component", entry["labels"].(map[string]any)["caller"])
 }
 
+func TestTraceFieldUsesProjectID(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"), gclog.WithProjectID("lingo-prod"))
+	require.NoError(t, err)
+
+	ctx := gclog.StubTraceContext(context.Background(), "1234abcd", "0011")
+	gclog.NewHelper(gclog.WithTrace(ctx, logger)).InfoWithPayload("done", nil)
+	entry := decodeEntry(t, buf.String())
+	require.Equal(t, "projects/lingo-prod/traces/0000000000000000000000001234abcd", entry["logging.googleapis.com/trace"])
+
+	buf.Reset()
+	require.NoError(t, logger.Log(log.LevelInfo, log.DefaultMessageKey, "no trace", "trace_sampled", true))
+	entry = decodeEntry(t, buf.String())
+	require.NotContains(t, entry, "logging.googleapis.com/trace")
+	require.NotContains(t, entry, "logging.googleapis.com/trace_sampled", "sampling flag requires a trace")
+}
+
+func TestTraceSampledFlag(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	tid, _ := trace.TraceIDFromHex("abcd1234abcd1234abcd1234abcd1234")
+	sid, _ := trace.SpanIDFromHex("1234abcd1234abcd")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid}))
+	gclog.NewHelper(gclog.WithTrace(ctx, logger)).InfoWithPayload("unsampled", nil)
+
+	entry := decodeEntry(t, buf.String())
+	require.Equal(t, "abcd1234abcd1234abcd1234abcd1234", entry["logging.googleapis.com/trace"])
+	require.NotContains(t, entry, "logging.googleapis.com/trace_sampled")
+}
+
+func TestComponentDetectsProjectInBackground(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	// A metadata server that never answers: a synchronous probe would block for its full timeout.
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(srv.URL, "http://"))
+
+	start := time.Now()
+	_, cleanup, err := gclog.NewComponent(gclog.Config{Service: "svc", Version: "v1"})
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 500*time.Millisecond, "project detection must not block construction")
+	cleanup()
+}
+
+func TestProjectIDFromEnvironment(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "lingo-staging")
+	require.Equal(t, "lingo-staging", gclog.DetectProjectID(context.Background()))
+
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+	ctx := gclog.StubTraceContext(context.Background(), "1234abcd", "0011")
+	gclog.NewHelper(gclog.WithTrace(ctx, logger)).InfoWithPayload("done", nil)
+	entry := decodeEntry(t, buf.String())
+	require.Equal(t, "projects/lingo-staging/traces/0000000000000000000000001234abcd", entry["logging.googleapis.com/trace"])
+}
+
 func TestLoggerRejectsUnsupportedKey(t *testing.T) {
-	logger, err := gclog.NewLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	logger, err := gclog.NewLogger(gclog.WithService("svc"), gclog.WithVersion("v1"), gclog.WithUnknownKeyPolicy(gclog.UnknownKeyStrict))
 	require.NoError(t, err)
 
 	err = logger.Log(log.LevelInfo, "foo", "bar")
@@ -375,6 +442,23 @@ func TestLabelsHelpers(t *testing.T) {
 	require.Equal(t, "growth", labels["team"])
 }
 
+func TestContextLabels(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	ctx := gclog.ContextWithLabels(context.Background(), map[string]string{"caller_email": "svc-a@example.com"})
+	ctx = gclog.ContextWithLabels(ctx, map[string]string{"request_id": "req-1"})
+	require.Equal(t, map[string]string{"caller_email": "svc-a@example.com", "request_id": "req-1"}, gclog.LabelsFromContext(ctx))
+
+	helper := gclog.RequestLogger(ctx, logger, "", map[string]string{"request_id": "req-override"}, nil)
+	helper.Logger().Log(log.LevelInfo, log.DefaultMessageKey, "msg")
+
+	labels := decodeEntry(t, buf.String())["labels"].(map[string]any)
+	require.Equal(t, "svc-a@example.com", labels["caller_email"])
+	require.Equal(t, "req-override", labels["request_id"], "explicit labels win over context labels")
+	require.Nil(t, gclog.LabelsFromContext(context.Background()))
+}
+
 func TestSourceLocationEnabled(t *testing.T) {
 	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"), gclog.EnableSourceLocation())
 	require.NoError(t, err)
@@ -0,0 +1,81 @@
+package gclog_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gclog "github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationFirstAndLast(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	ctx := gclog.StartOperation(context.Background(), "batch-1", "outbox/publisher")
+	op := gclog.OperationFromContext(ctx)
+	require.Equal(t, "batch-1", op.ID)
+	require.Equal(t, "outbox/publisher", op.Producer)
+
+	require.NoError(t, gclog.WithOperation(ctx, logger).Log(log.LevelInfo, log.DefaultMessageKey, "start"))
+	require.NoError(t, gclog.RequestLogger(ctx, logger, "", nil, nil).Logger().Log(log.LevelInfo, log.DefaultMessageKey, "middle"))
+	require.NoError(t, gclog.WithOperation(gclog.EndOperation(ctx), logger).Log(log.LevelInfo, log.DefaultMessageKey, "end"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	want := []map[string]any{
+		{"id": "batch-1", "producer": "outbox/publisher", "first": true},
+		{"id": "batch-1", "producer": "outbox/publisher"},
+		{"id": "batch-1", "producer": "outbox/publisher", "last": true},
+	}
+	for i, line := range lines {
+		require.Equal(t, want[i], decodeEntry(t, line)["logging.googleapis.com/operation"], "entry %d", i)
+	}
+}
+
+func TestOperationFirstSkipsFilteredEntries(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"), gclog.WithMinLevel(log.LevelInfo))
+	require.NoError(t, err)
+
+	ctx := gclog.StartOperation(context.Background(), "msg-1", "")
+	opLogger := gclog.WithOperation(ctx, logger)
+	require.NoError(t, opLogger.Log(log.LevelDebug, log.DefaultMessageKey, "filtered"))
+	require.NoError(t, opLogger.Log(log.LevelInfo, log.DefaultMessageKey, "kept"))
+
+	require.Equal(t, map[string]any{"id": "msg-1", "first": true}, decodeEntry(t, buf.String())["logging.googleapis.com/operation"])
+}
+
+func TestOperationAbsent(t *testing.T) {
+	ctx := context.Background()
+	require.Nil(t, gclog.OperationFromContext(ctx))
+	require.Equal(t, ctx, gclog.StartOperation(ctx, "", "p"))
+	require.Equal(t, ctx, gclog.EndOperation(ctx))
+
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+	require.NoError(t, gclog.WithOperation(ctx, logger).Log(log.LevelInfo, log.DefaultMessageKey, "plain"))
+	require.NotContains(t, decodeEntry(t, buf.String()), "logging.googleapis.com/operation")
+}
+
+func TestInsertID(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	seen := map[any]bool{}
+	for i := 0; i < 50; i++ {
+		require.NoError(t, logger.Log(log.LevelInfo, log.DefaultMessageKey, "msg"))
+	}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		id := decodeEntry(t, line)["logging.googleapis.com/insertId"]
+		require.NotEmpty(t, id)
+		require.False(t, seen[id], "duplicate insertId %v", id)
+		seen[id] = true
+	}
+
+	logger, buf, err = gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"), gclog.DisableInsertID())
+	require.NoError(t, err)
+	require.NoError(t, logger.Log(log.LevelInfo, log.DefaultMessageKey, "msg"))
+	require.NotContains(t, decodeEntry(t, buf.String()), "logging.googleapis.com/insertId")
+}
@@ -0,0 +1,101 @@
+package gclog_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	gclog "github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+type memLogExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *memLogExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *memLogExporter) Shutdown(context.Context) error   { return nil }
+func (e *memLogExporter) ForceFlush(context.Context) error { return nil }
+
+func recordAttributes(r sdklog.Record) map[string]otellog.Value {
+	attrs := map[string]otellog.Value{}
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	return attrs
+}
+
+func TestOTelLogsBridge(t *testing.T) {
+	exp := &memLogExporter{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp)))
+	t.Cleanup(func() { _ = lp.Shutdown(context.Background()) })
+
+	logger, buf, err := gclog.NewTestLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithLoggerProvider(lp),
+	)
+	require.NoError(t, err)
+
+	ctx := gclog.StubTraceContext(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	ctx = gclog.StartOperation(ctx, "batch-1", "outbox/publisher")
+	l := gclog.WithOperation(ctx, gclog.WithTrace(ctx, logger))
+	l = gclog.WithLabels(l, map[string]string{"tenant": "t-1"})
+	l = gclog.WithPayload(l, map[string]any{"count": 3})
+	l = gclog.WithHTTPRequest(l, httptest.NewRequest("GET", "/v1/videos", nil), 503, 25*time.Millisecond)
+	require.NoError(t, l.Log(log.LevelError, log.DefaultMessageKey, "upstream failed"))
+	require.NotEmpty(t, buf.String())
+
+	require.Len(t, exp.records, 1)
+	rec := exp.records[0]
+	require.Equal(t, otellog.SeverityError, rec.Severity())
+	require.Equal(t, "ERROR", rec.SeverityText())
+	require.Equal(t, "upstream failed", rec.Body().AsString())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rec.TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", rec.SpanID().String())
+	require.Equal(t, "github.com/bionicotaku/lingo-utils/gclog", rec.InstrumentationScope().Name)
+
+	attrs := recordAttributes(rec)
+	require.Equal(t, "t-1", attrs["tenant"].AsString())
+	require.Equal(t, "batch-1", attrs["gclog.operation.id"].AsString())
+	require.True(t, attrs["gclog.operation.first"].AsBool())
+	require.Equal(t, "GET", attrs["http.request.method"].AsString())
+	require.Equal(t, int64(503), attrs["http.response.status_code"].AsInt64())
+	require.InDelta(t, 0.025, attrs["http.server.request.duration"].AsFloat64(), 1e-9)
+	require.NotEmpty(t, attrs["log.record.uid"].AsString())
+	require.Contains(t, attrs, "payload")
+}
+
+func TestOTelLogsBridgeSkipsFilteredEntries(t *testing.T) {
+	exp := &memLogExporter{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp)))
+	t.Cleanup(func() { _ = lp.Shutdown(context.Background()) })
+
+	logger, _, err := gclog.NewTestLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithMinLevel(log.LevelInfo),
+		gclog.WithLoggerProvider(lp),
+	)
+	require.NoError(t, err)
+	require.NoError(t, logger.Log(log.LevelDebug, log.DefaultMessageKey, "filtered"))
+	require.NoError(t, logger.Log(log.LevelWarn, log.DefaultMessageKey, "kept"))
+
+	require.Len(t, exp.records, 1)
+	require.Equal(t, otellog.SeverityWarn, exp.records[0].Severity())
+}
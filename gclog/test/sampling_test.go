@@ -0,0 +1,167 @@
+package gclog_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gclog "github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplerInitialThenEveryMth(t *testing.T) {
+	s := gclog.NewSampler(gclog.SampleInitial(3), gclog.SampleThereafter(5))
+	now := time.Now()
+
+	kept := 0
+	for i := 0; i < 23; i++ {
+		if s.Allow(log.LevelInfo, "publish ok", now) {
+			kept++
+		}
+	}
+	// 3 initial + #8, #13, #18, #23.
+	require.Equal(t, 7, kept)
+
+	// 新窗口重新计数。
+	require.True(t, s.Allow(log.LevelInfo, "publish ok", now.Add(2*time.Second)))
+	// 不同级别/消息互不影响。
+	require.True(t, s.Allow(log.LevelDebug, "publish ok", now))
+	require.True(t, s.Allow(log.LevelInfo, "other", now))
+}
+
+func TestSamplerKeepsWarnAndAboveByDefault(t *testing.T) {
+	s := gclog.NewSampler(gclog.SampleInitial(1), gclog.SampleThereafter(0))
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		require.True(t, s.Allow(log.LevelWarn, "retrying", now))
+	}
+	require.True(t, s.Allow(log.LevelInfo, "tick", now))
+	require.False(t, s.Allow(log.LevelInfo, "tick", now))
+
+	s = gclog.NewSampler(gclog.SampleInitial(1), gclog.SampleThereafter(0), gclog.SampleMaxLevel(log.LevelWarn))
+	require.True(t, s.Allow(log.LevelWarn, "retrying", now))
+	require.False(t, s.Allow(log.LevelWarn, "retrying", now))
+}
+
+func TestLoggerSamplingWritesSummary(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithSampler(gclog.NewSampler(
+			gclog.SampleInitial(2),
+			gclog.SampleThereafter(0),
+			gclog.SampleSummaryInterval(time.Hour),
+		)),
+	)
+	require.NoError(t, err)
+	helper := log.NewHelper(logger)
+
+	for i := 0; i < 10; i++ {
+		helper.Info("received message")
+		helper.Error("handler failed")
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 12)
+
+	require.NoError(t, logger.(*gclog.Logger).Close())
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 13)
+
+	summary := decodeEntry(t, lines[12])
+	require.Equal(t, "WARNING", summary["severity"])
+	require.Equal(t, "gclog: sampling suppressed 8 log entries", summary["message"])
+	payload := summary["jsonPayload"].(map[string]any)
+	require.Equal(t, float64(8), payload["suppressed_total"])
+	require.Equal(t, float64(8), payload["suppressed"].(map[string]any)["received message"])
+
+	// 汇总只输出一次。
+	require.NoError(t, logger.(*gclog.Logger).Close())
+	require.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 13)
+}
+
+func TestLoggerSamplingSummaryOnInterval(t *testing.T) {
+	out := &syncBuffer{}
+	logger, err := gclog.NewLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithWriter(out),
+		gclog.WithSampler(gclog.NewSampler(
+			gclog.SampleInitial(1),
+			gclog.SampleThereafter(0),
+			gclog.SampleSummaryInterval(20*time.Millisecond),
+		)),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = logger.(*gclog.Logger).Close() })
+	helper := log.NewHelper(logger)
+
+	helper.Debug("poll")
+	helper.Debug("poll")
+
+	// The summary is driven by the logger's ticker, not by the next entry.
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "gclog: sampling suppressed 1 log entries")
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSamplerWindowResetUnderContention(t *testing.T) {
+	sampler := gclog.NewSampler(gclog.SampleInitial(10), gclog.SampleThereafter(0), gclog.SampleTick(time.Hour))
+	now := time.Now()
+
+	var (
+		wg      sync.WaitGroup
+		allowed atomic.Int64
+	)
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if sampler.Allow(log.LevelInfo, "burst", now) {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 10, allowed.Load(), "a window opened concurrently must admit exactly the initial quota")
+}
+
+// syncBuffer is a bytes.Buffer safe for the logger's background writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestComponentSamplingConfig(t *testing.T) {
+	_, cleanup, err := gclog.NewComponent(gclog.Config{
+		Service:   "svc",
+		Version:   "v1",
+		ProjectID: "proj",
+		Sampling:  &gclog.SamplingConfig{Initial: 10, MaxLevel: "debug"},
+	})
+	require.NoError(t, err)
+	cleanup()
+
+	_, _, err = gclog.NewComponent(gclog.Config{
+		Service:   "svc",
+		Version:   "v1",
+		ProjectID: "proj",
+		Sampling:  &gclog.SamplingConfig{MaxLevel: "loud"},
+	})
+	require.Error(t, err)
+}
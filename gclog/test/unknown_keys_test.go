@@ -0,0 +1,83 @@
+package gclog_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gclog "github.com/bionicotaku/lingo-utils/gclog"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestUnknownKeysFoldedByDefault(t *testing.T) {
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("svc"), gclog.WithVersion("v1"))
+	require.NoError(t, err)
+
+	log.NewHelper(logger).Errorw(log.DefaultMessageKey, "publish failed", "event_id", "evt-1")
+
+	entry := decodeEntry(t, buf.String())
+	require.Equal(t, "publish failed", entry["message"])
+	require.Equal(t, "evt-1", entry["jsonPayload"].(map[string]any)["event_id"])
+}
+
+func TestUnknownKeysDroppedWithSingleWarning(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	logger, buf, err := gclog.NewTestLogger(
+		gclog.WithService("svc"),
+		gclog.WithVersion("v1"),
+		gclog.WithUnknownKeyPolicy(gclog.UnknownKeyDrop),
+		gclog.WithMeter(meter),
+	)
+	require.NoError(t, err)
+
+	helper := log.NewHelper(logger)
+	helper.Errorw(log.DefaultMessageKey, "first", "event_id", "evt-1")
+	helper.Errorw(log.DefaultMessageKey, "second", "event_id", "evt-2")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3, "one warning plus both entries")
+	warning := decodeEntry(t, lines[0])
+	require.Equal(t, "WARNING", warning["severity"])
+	require.Contains(t, warning["message"], `"event_id"`)
+	for i, msg := range []string{"first", "second"} {
+		entry := decodeEntry(t, lines[i+1])
+		require.Equal(t, msg, entry["message"])
+		require.NotContains(t, entry, "jsonPayload")
+	}
+
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &data))
+	var total int64
+	for _, sm := range data.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "gclog_unknown_keys_total" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				action, _ := dp.Attributes.Value("gclog.action")
+				require.Equal(t, "dropped", action.AsString())
+				total += dp.Value
+			}
+		}
+	}
+	require.EqualValues(t, 2, total)
+}
+
+func TestParseUnknownKeyPolicy(t *testing.T) {
+	for in, want := range map[string]gclog.UnknownKeyPolicy{
+		"":       gclog.UnknownKeyFold,
+		"fold":   gclog.UnknownKeyFold,
+		"drop":   gclog.UnknownKeyDrop,
+		"strict": gclog.UnknownKeyStrict,
+	} {
+		got, err := gclog.ParseUnknownKeyPolicy(in)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+	_, err := gclog.ParseUnknownKeyPolicy("ignore")
+	require.Error(t, err)
+}
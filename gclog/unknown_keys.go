@@ -0,0 +1,103 @@
+package gclog
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// UnknownKeyPolicy decides what happens to keys outside the allowlist
+// (core fields, Kratos defaults, WithAllowedKeys / WithAllowedLabelKeys).
+type UnknownKeyPolicy int
+
+const (
+	// UnknownKeyFold writes unknown keys into the top level of jsonPayload (default).
+	UnknownKeyFold UnknownKeyPolicy = iota
+	// UnknownKeyDrop removes only the unknown key and emits one WARNING entry per key.
+	UnknownKeyDrop
+	// UnknownKeyStrict rejects the whole entry with an error. Kratos ignores Log
+	// errors, so the line is lost; use it in tests to catch unregistered keys.
+	UnknownKeyStrict
+)
+
+// String implements fmt.Stringer.
+func (p UnknownKeyPolicy) String() string {
+	switch p {
+	case UnknownKeyDrop:
+		return "drop"
+	case UnknownKeyStrict:
+		return "strict"
+	default:
+		return "fold"
+	}
+}
+
+// ParseUnknownKeyPolicy parses fold/drop/strict; an empty string yields fold.
+func ParseUnknownKeyPolicy(s string) (UnknownKeyPolicy, error) {
+	switch s {
+	case "", "fold":
+		return UnknownKeyFold, nil
+	case "drop":
+		return UnknownKeyDrop, nil
+	case "strict":
+		return UnknownKeyStrict, nil
+	}
+	return UnknownKeyFold, fmt.Errorf("gclog: unknown key policy %q", s)
+}
+
+// WithUnknownKeyPolicy selects how keys outside the allowlist are handled.
+func WithUnknownKeyPolicy(p UnknownKeyPolicy) Option {
+	return func(o *Options) {
+		o.UnknownKeys = p
+	}
+}
+
+// WithMeter sets the meter recording gclog_unknown_keys_total (defaults to the global MeterProvider).
+func WithMeter(meter metric.Meter) Option {
+	return func(o *Options) {
+		o.Meter = meter
+	}
+}
+
+func newUnknownKeyCounter(meter metric.Meter) metric.Int64Counter {
+	if meter == nil {
+		meter = otel.GetMeterProvider().Meter("lingo-utils/gclog")
+	}
+	counter, err := meter.Int64Counter("gclog_unknown_keys_total",
+		metric.WithDescription("Log fields outside the gclog allowlist, by key and action (folded/dropped/rejected)."))
+	if err != nil {
+		return nil
+	}
+	return counter
+}
+
+// unknownKey applies the policy to key and reports whether the entry may still be written.
+func (l *Logger) unknownKey(key string) (keep bool, err error) {
+	switch l.opts.UnknownKeys {
+	case UnknownKeyStrict:
+		l.countUnknown(key, "rejected")
+		return false, fmt.Errorf("gclog: unsupported log field %q", key)
+	case UnknownKeyDrop:
+		l.countUnknown(key, "dropped")
+		if _, warned := l.warnedKeys.LoadOrStore(key, struct{}{}); !warned {
+			_ = l.write(l.internalEntry(fmt.Sprintf("gclog: dropped unsupported log field %q; register it with WithAllowedKeys", key)))
+		}
+		return false, nil
+	default:
+		l.countUnknown(key, "folded")
+		return true, nil
+	}
+}
+
+func (l *Logger) countUnknown(key, action string) {
+	if l.unknownKeys == nil {
+		return
+	}
+	l.unknownKeys.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("gclog.key", key),
+		attribute.String("gclog.action", action),
+	))
+}
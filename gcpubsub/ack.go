@@ -0,0 +1,215 @@
+package gcpubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+)
+
+const defaultAckResultTimeout = 10 * time.Second
+
+// ErrPermanent 表示消息无法通过重试恢复；处理器返回包装该错误时消息会被 Ack，不再重投。
+var ErrPermanent = errors.New("gcpubsub: permanent failure")
+
+// ErrLeaseExceeded 表示所需的处理时间超出 ReceiveConfig.MaxExtension 剩余的租约预算。
+var ErrLeaseExceeded = errors.New("gcpubsub: lease extension exceeds max extension")
+
+// RetryAfterError 指示消息在给定延迟后再 Nack 以触发重投。
+type RetryAfterError struct {
+	Delay time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("gcpubsub: retry after %s", e.Delay)
+}
+
+// ErrRetryAfter 构造 RetryAfterError，处理器返回后消息会延迟 d 再 Nack。
+func ErrRetryAfter(d time.Duration) error {
+	return &RetryAfterError{Delay: d}
+}
+
+// RetryAfter 从错误链中提取重试延迟。
+func RetryAfter(err error) (time.Duration, bool) {
+	var target *RetryAfterError
+	if errors.As(err, &target) {
+		return target.Delay, true
+	}
+	return 0, false
+}
+
+// AckHandle 允许处理器显式控制消息确认；首次调用生效，后续调用被忽略。
+type AckHandle interface {
+	// Ack 确认消息；启用 ExactlyOnceDelivery 时等待服务端确认结果。
+	Ack() error
+	// Nack 拒绝消息以立即重投；启用 ExactlyOnceDelivery 时等待服务端确认结果。
+	Nack() error
+	// NackWithDelay 持有消息 d 后再 Nack，延迟受 MaxExtension 限制，结果异步记录。
+	NackWithDelay(d time.Duration)
+	// CheckLease 检查剩余租约预算是否还够 d：续租由客户端按 MaxExtension 自动完成，
+	// 此方法不发送 modack，预算不足时返回 ErrLeaseExceeded，处理器可据此提前放弃或拆分工作。
+	CheckLease(d time.Duration) error
+}
+
+// AckHandlerFunc 是可显式控制 Ack 行为的处理器签名。
+type AckHandlerFunc func(ctx context.Context, msg *Message, ack AckHandle) error
+
+const (
+	ackActionAck  = "ack"
+	ackActionNack = "nack"
+)
+
+// ackHandle 包装 pubsub.Message，保证确认动作只执行一次。
+type ackHandle struct {
+	ctx          context.Context
+	msg          *pubsub.Message
+	sub          *subscriber
+	received     time.Time
+	maxExtension time.Duration
+
+	once   sync.Once
+	mu     sync.Mutex
+	action string
+	err    error
+}
+
+func newAckHandle(ctx context.Context, msg *pubsub.Message, sub *subscriber, received time.Time) *ackHandle {
+	return &ackHandle{
+		ctx:          ctx,
+		msg:          msg,
+		sub:          sub,
+		received:     received,
+		maxExtension: sub.maxExtension,
+	}
+}
+
+func (h *ackHandle) Ack() error {
+	h.settle(ackActionAck)
+	return h.outcome()
+}
+
+func (h *ackHandle) Nack() error {
+	h.settle(ackActionNack)
+	return h.outcome()
+}
+
+func (h *ackHandle) NackWithDelay(d time.Duration) {
+	if d <= 0 {
+		h.settle(ackActionNack)
+		return
+	}
+	if remaining := h.remainingLease(); d > remaining {
+		d = remaining
+	}
+	h.once.Do(func() {
+		h.mu.Lock()
+		h.action = ackActionNack
+		h.mu.Unlock()
+		// 处理器仍持有在途计数，此处 Add 不会与 Stop 中的 Wait 竞争；Stop 会等待延迟 Nack 完成。
+		h.sub.inflight.Add(1)
+		go func() {
+			defer h.sub.inflight.Done()
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-h.ctx.Done():
+			case <-h.sub.abortCtx.Done():
+			}
+			h.apply(ackActionNack)
+		}()
+	})
+}
+
+func (h *ackHandle) CheckLease(d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	if d > h.remainingLease() {
+		return ErrLeaseExceeded
+	}
+	return nil
+}
+
+// settled 返回处理器是否已做出确认决定。
+func (h *ackHandle) settled() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.action != ""
+}
+
+func (h *ackHandle) settle(action string) {
+	h.once.Do(func() {
+		h.mu.Lock()
+		h.action = action
+		h.mu.Unlock()
+		h.apply(action)
+	})
+}
+
+func (h *ackHandle) outcome() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+func (h *ackHandle) apply(action string) {
+	if !h.sub.exactlyOnce {
+		if action == ackActionAck {
+			h.msg.Ack()
+		} else {
+			h.msg.Nack()
+		}
+		h.sub.recordAck(h.ctx, h.msg, action, nil)
+		return
+	}
+
+	var result *pubsub.AckResult
+	if action == ackActionAck {
+		result = h.msg.AckWithResult()
+	} else {
+		result = h.msg.NackWithResult()
+	}
+
+	waitCtx, cancel := context.WithTimeout(context.WithoutCancel(h.ctx), defaultAckResultTimeout)
+	defer cancel()
+	status, err := result.Get(waitCtx)
+	if err == nil && status != pubsub.AcknowledgeStatusSuccess {
+		err = fmt.Errorf("gcpubsub: %s status %s", action, ackStatusName(status))
+	}
+
+	h.mu.Lock()
+	h.err = err
+	h.mu.Unlock()
+	h.sub.recordAck(h.ctx, h.msg, action, err)
+}
+
+func (h *ackHandle) remainingLease() time.Duration {
+	if h.maxExtension <= 0 {
+		return 0
+	}
+	remaining := h.maxExtension - h.sub.clock().Sub(h.received)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// ackStatusName 将 AcknowledgeStatus 转换为指标与日志使用的标签。
+func ackStatusName(status pubsub.AcknowledgeStatus) string {
+	switch status {
+	case pubsub.AcknowledgeStatusSuccess:
+		return "success"
+	case pubsub.AcknowledgeStatusPermissionDenied:
+		return "permission_denied"
+	case pubsub.AcknowledgeStatusFailedPrecondition:
+		return "failed_precondition"
+	case pubsub.AcknowledgeStatusInvalidAckID:
+		return "invalid_ack_id"
+	default:
+		return "other"
+	}
+}
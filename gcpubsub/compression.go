@@ -0,0 +1,122 @@
+package gcpubsub
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// AttrContentEncoding 标记载荷压缩算法的消息属性；消费端据此透明解压。
+const AttrContentEncoding = "content-encoding"
+
+const (
+	// CompressionNone 表示不压缩。
+	CompressionNone = ""
+	// CompressionGzip 使用 gzip 压缩。
+	CompressionGzip = "gzip"
+	// CompressionZstd 使用 zstd 压缩。
+	CompressionZstd = "zstd"
+)
+
+// ErrMessageTooLarge 表示消息超过 MaxMessageBytes，可用 errors.Is 判断。
+var ErrMessageTooLarge = errors.New("gcpubsub: message too large")
+
+// MessageTooLargeError 描述超限消息的实际大小与上限。
+type MessageTooLargeError struct {
+	Topic string
+	Size  int
+	Limit int
+}
+
+func (e *MessageTooLargeError) Error() string {
+	return fmt.Sprintf("gcpubsub: message for topic %s is %d bytes, exceeds limit %d", e.Topic, e.Size, e.Limit)
+}
+
+// Is 使 errors.Is(err, ErrMessageTooLarge) 成立。
+func (e *MessageTooLargeError) Is(target error) bool {
+	return target == ErrMessageTooLarge
+}
+
+// errUnsupportedEncoding 在收到无法识别的 content-encoding 时返回。
+var errUnsupportedEncoding = errors.New("gcpubsub: unsupported content-encoding")
+
+var zstdEncoder, _ = zstd.NewWriter(nil)
+
+// compressPayload 使用指定算法压缩载荷。
+func compressPayload(algorithm string, data []byte) ([]byte, error) {
+	switch algorithm {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("gcpubsub: gzip compress: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("gcpubsub: gzip compress: %w", err)
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		return zstdEncoder.EncodeAll(data, make([]byte, 0, len(data)/2)), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedEncoding, algorithm)
+	}
+}
+
+// isCompressionEncoding 判断 content-encoding 是否为本库写入的压缩算法。
+func isCompressionEncoding(encoding string) bool {
+	return encoding == CompressionGzip || encoding == CompressionZstd
+}
+
+// decompressPayload 按 content-encoding 解压载荷，解压后大小超过 limit 时返回错误。
+func decompressPayload(encoding string, data []byte, limit int) ([]byte, error) {
+	switch encoding {
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("gcpubsub: gzip decompress: %w", err)
+		}
+		defer r.Close()
+		out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+		if err != nil {
+			return nil, fmt.Errorf("gcpubsub: gzip decompress: %w", err)
+		}
+		if len(out) > limit {
+			return nil, fmt.Errorf("gcpubsub: decompressed payload exceeds %d bytes", limit)
+		}
+		return out, nil
+	case CompressionZstd:
+		// 流式解码并按 limit 截断，避免 DecodeAll 先把整段载荷展开再检查大小；
+		// 解码器内存同样以 limit 为上限，拒绝声明超大窗口的帧。
+		r, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(limit)))
+		if err != nil {
+			return nil, fmt.Errorf("gcpubsub: zstd decompress: %w", err)
+		}
+		defer r.Close()
+		if err := r.Reset(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("gcpubsub: zstd decompress: %w", err)
+		}
+		out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+		if err != nil {
+			return nil, fmt.Errorf("gcpubsub: zstd decompress: %w", err)
+		}
+		if len(out) > limit {
+			return nil, fmt.Errorf("gcpubsub: decompressed payload exceeds %d bytes", limit)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedEncoding, encoding)
+	}
+}
+
+// messageSize 估算 Pub/Sub 计费与限额使用的消息大小（载荷、属性与排序键）。
+func messageSize(data []byte, attrs map[string]string, orderingKey string) int {
+	size := len(data) + len(orderingKey)
+	for k, v := range attrs {
+		size += len(k) + len(v)
+	}
+	return size
+}
@@ -1,7 +1,10 @@
 // Package gcpubsub 提供 Google Cloud Pub/Sub 组件的配置定义。
 package gcpubsub
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 const (
 	defaultPublishTimeout         = 10 * time.Second
@@ -11,8 +14,16 @@ const (
 	defaultMaxOutstandingBytes    = 64 << 20 // 64 MiB
 	defaultMaxExtension           = time.Minute
 	defaultMaxExtensionPeriod     = 10 * time.Minute
+	defaultShutdownTimeout        = 8 * time.Second
+	defaultRouteAttribute         = "event_type"
+	defaultMaxMessageBytes        = 10_000_000 // Pub/Sub 单条消息上限 10 MB
+	defaultCompressionThreshold   = 64 << 10   // 64 KiB
+	defaultMaxDecompressedBytes   = 64 << 20   // 64 MiB
 )
 
+// DefaultName 是旧版单 Topic / Subscription 配置折叠后使用的名称。
+const DefaultName = "default"
+
 // Config 定义 gcpubsub 组件的运行参数。
 type Config struct {
 	ProjectID           string        `json:"projectID" yaml:"projectID"`
@@ -26,6 +37,63 @@ type Config struct {
 	EmulatorEndpoint    string        `json:"emulatorEndpoint" yaml:"emulatorEndpoint"`
 	Receive             ReceiveConfig `json:"receive" yaml:"receive"`
 	ExactlyOnceDelivery bool          `json:"exactlyOnceDelivery" yaml:"exactlyOnceDelivery"`
+
+	// Topics 声明具名 Topic，所有 Topic 共享同一个 pubsub.Client；TopicID 会折叠为 "default"。
+	Topics map[string]TopicConfig `json:"topics" yaml:"topics"`
+	// Subscriptions 声明具名 Subscription；SubscriptionID 会折叠为 "default"。
+	Subscriptions map[string]SubscriptionConfig `json:"subscriptions" yaml:"subscriptions"`
+	// Routing 控制 Router 按消息属性选择 Topic 的规则。
+	Routing RoutingConfig `json:"routing" yaml:"routing"`
+	// Schema 控制本地 schema 校验，需配合 Dependencies.Schemas 使用。
+	Schema SchemaConfig `json:"schema" yaml:"schema"`
+	// MaxMessageBytes 为发布前校验的消息大小上限（载荷 + 属性 + 排序键），默认 10,000,000。
+	MaxMessageBytes int `json:"maxMessageBytes" yaml:"maxMessageBytes"`
+	// Compression 控制发布端载荷压缩；消费端始终按 content-encoding 属性解压。
+	Compression CompressionConfig `json:"compression" yaml:"compression"`
+}
+
+// CompressionConfig 定义载荷压缩策略。
+type CompressionConfig struct {
+	// Algorithm 取值 "" / "gzip" / "zstd"，为空表示发布端不压缩。
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+	// ThresholdBytes 载荷达到该大小才压缩，默认 64 KiB。
+	ThresholdBytes int `json:"thresholdBytes" yaml:"thresholdBytes"`
+	// MaxDecompressedBytes 消费端解压后的大小上限，默认 64 MiB。
+	MaxDecompressedBytes int `json:"maxDecompressedBytes" yaml:"maxDecompressedBytes"`
+}
+
+// SchemaConfig 定义本地 schema 校验开关。
+type SchemaConfig struct {
+	// ValidatePublish 发布前校验载荷；nil 表示默认 true。
+	ValidatePublish *bool `json:"validatePublish" yaml:"validatePublish"`
+	// ValidateReceive 调用处理器前校验载荷；nil 表示默认 true。
+	ValidateReceive *bool `json:"validateReceive" yaml:"validateReceive"`
+	// CompareTopicSchemas 在启动时拉取 Topic 绑定的 schema 与本地校验器比对，仅记录告警。
+	CompareTopicSchemas bool `json:"compareTopicSchemas" yaml:"compareTopicSchemas"`
+}
+
+// TopicConfig 描述单个具名 Topic，未设置的字段继承 Config 顶层值。
+type TopicConfig struct {
+	TopicID            string        `json:"topicID" yaml:"topicID"`
+	PublishTimeout     time.Duration `json:"publishTimeout" yaml:"publishTimeout"`
+	OrderingKeyEnabled *bool         `json:"orderingKeyEnabled" yaml:"orderingKeyEnabled"`
+}
+
+// SubscriptionConfig 描述单个具名 Subscription，未设置的字段继承 Config 顶层值。
+type SubscriptionConfig struct {
+	SubscriptionID      string        `json:"subscriptionID" yaml:"subscriptionID"`
+	Receive             ReceiveConfig `json:"receive" yaml:"receive"`
+	ExactlyOnceDelivery *bool         `json:"exactlyOnceDelivery" yaml:"exactlyOnceDelivery"`
+}
+
+// RoutingConfig 定义 Router 的路由表。
+type RoutingConfig struct {
+	// DefaultTopic 为未命中路由时使用的 Topic 名称；为空时取 "default" 或唯一的 Topic。
+	DefaultTopic string `json:"defaultTopic" yaml:"defaultTopic"`
+	// Attribute 为路由键所在的消息属性，默认 "event_type"，可改为 "aggregate_type"。
+	Attribute string `json:"attribute" yaml:"attribute"`
+	// Routes 将路由键映射到 Topic 名称。
+	Routes map[string]string `json:"routes" yaml:"routes"`
 }
 
 // ReceiveConfig 定义 StreamingPull 的并发与流控设置。
@@ -35,6 +103,8 @@ type ReceiveConfig struct {
 	MaxOutstandingBytes    int           `json:"maxOutstandingBytes" yaml:"maxOutstandingBytes"`
 	MaxExtension           time.Duration `json:"maxExtension" yaml:"maxExtension"`
 	MaxExtensionPeriod     time.Duration `json:"maxExtensionPeriod" yaml:"maxExtensionPeriod"`
+	// ShutdownTimeout 为组件清理时等待在途处理器完成的上限，默认 8s（Cloud Run SIGTERM 宽限期约 10s）。
+	ShutdownTimeout time.Duration `json:"shutdownTimeout" yaml:"shutdownTimeout"`
 }
 
 // Normalize 返回填充默认值后的配置副本。
@@ -64,9 +134,110 @@ func (c Config) Normalize() Config {
 		s.ExactlyOnceDelivery = false
 	}
 
+	if s.Schema.ValidatePublish == nil {
+		s.Schema.ValidatePublish = boolPtr(true)
+	}
+	if s.Schema.ValidateReceive == nil {
+		s.Schema.ValidateReceive = boolPtr(true)
+	}
+
+	if s.MaxMessageBytes <= 0 {
+		s.MaxMessageBytes = defaultMaxMessageBytes
+	}
+	if s.Compression.ThresholdBytes <= 0 {
+		s.Compression.ThresholdBytes = defaultCompressionThreshold
+	}
+	if s.Compression.MaxDecompressedBytes <= 0 {
+		s.Compression.MaxDecompressedBytes = defaultMaxDecompressedBytes
+	}
+
+	s.Topics = s.normalizeTopics()
+	s.Subscriptions = s.normalizeSubscriptions()
+	s.Routing = s.normalizeRouting()
+
 	return s
 }
 
+func (c Config) normalizeTopics() map[string]TopicConfig {
+	topics := make(map[string]TopicConfig, len(c.Topics)+1)
+	for name, tc := range c.Topics {
+		topics[name] = tc
+	}
+	if _, ok := topics[DefaultName]; !ok && c.TopicID != "" {
+		topics[DefaultName] = TopicConfig{TopicID: c.TopicID}
+	}
+	for name, tc := range topics {
+		if tc.PublishTimeout <= 0 {
+			tc.PublishTimeout = c.PublishTimeout
+		}
+		if tc.OrderingKeyEnabled == nil {
+			tc.OrderingKeyEnabled = boolPtr(c.orderingEnabled())
+		}
+		topics[name] = tc
+	}
+	return topics
+}
+
+func (c Config) normalizeSubscriptions() map[string]SubscriptionConfig {
+	subs := make(map[string]SubscriptionConfig, len(c.Subscriptions)+1)
+	for name, sc := range c.Subscriptions {
+		subs[name] = sc
+	}
+	if _, ok := subs[DefaultName]; !ok && c.SubscriptionID != "" {
+		subs[DefaultName] = SubscriptionConfig{SubscriptionID: c.SubscriptionID}
+	}
+	for name, sc := range subs {
+		sc.Receive = sc.Receive.inherit(c.Receive).withDefaults()
+		if sc.ExactlyOnceDelivery == nil {
+			sc.ExactlyOnceDelivery = boolPtr(c.ExactlyOnceDelivery)
+		}
+		if c.EmulatorEndpoint != "" {
+			sc.ExactlyOnceDelivery = boolPtr(false)
+		}
+		subs[name] = sc
+	}
+	return subs
+}
+
+func (c Config) normalizeRouting() RoutingConfig {
+	r := c.Routing
+	if r.Attribute == "" {
+		r.Attribute = defaultRouteAttribute
+	}
+	if r.DefaultTopic == "" {
+		r.DefaultTopic = defaultName(c.Topics)
+	}
+	routes := make(map[string]string, len(r.Routes))
+	for key, topic := range r.Routes {
+		routes[key] = topic
+	}
+	r.Routes = routes
+	return r
+}
+
+// defaultName 返回 "default" 条目，若不存在且仅有一个条目则返回该条目名称。
+func defaultName[V any](entries map[string]V) string {
+	if _, ok := entries[DefaultName]; ok {
+		return DefaultName
+	}
+	if len(entries) == 1 {
+		for name := range entries {
+			return name
+		}
+	}
+	return ""
+}
+
+// sortedNames 返回按字典序排列的条目名称，保证构建与清理顺序稳定。
+func sortedNames[V any](entries map[string]V) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (rc ReceiveConfig) withDefaults() ReceiveConfig {
 	s := rc
 	if s.NumGoroutines <= 0 {
@@ -84,6 +255,33 @@ func (rc ReceiveConfig) withDefaults() ReceiveConfig {
 	if s.MaxExtensionPeriod <= 0 {
 		s.MaxExtensionPeriod = defaultMaxExtensionPeriod
 	}
+	if s.ShutdownTimeout <= 0 {
+		s.ShutdownTimeout = defaultShutdownTimeout
+	}
+	return s
+}
+
+// inherit 逐字段以 parent 填充未设置（<= 0）的字段，具名 Subscription 只覆盖显式设置的项。
+func (rc ReceiveConfig) inherit(parent ReceiveConfig) ReceiveConfig {
+	s := rc
+	if s.NumGoroutines <= 0 {
+		s.NumGoroutines = parent.NumGoroutines
+	}
+	if s.MaxOutstandingMessages <= 0 {
+		s.MaxOutstandingMessages = parent.MaxOutstandingMessages
+	}
+	if s.MaxOutstandingBytes <= 0 {
+		s.MaxOutstandingBytes = parent.MaxOutstandingBytes
+	}
+	if s.MaxExtension <= 0 {
+		s.MaxExtension = parent.MaxExtension
+	}
+	if s.MaxExtensionPeriod <= 0 {
+		s.MaxExtensionPeriod = parent.MaxExtensionPeriod
+	}
+	if s.ShutdownTimeout <= 0 {
+		s.ShutdownTimeout = parent.ShutdownTimeout
+	}
 	return s
 }
 
@@ -24,6 +24,10 @@ type Dependencies struct {
 	CredentialsJSON []byte
 	Dial            DialOptions
 	ClientFactory   ClientFactory
+	// Schemas 为可选的本地 schema 注册表，nil 表示不做校验。
+	Schemas *SchemaRegistry
+	// SchemaClientFactory 用于 Config.Schema.CompareTopicSchemas，nil 时使用默认实现。
+	SchemaClientFactory SchemaClientFactory
 }
 
 // DialOptions 描述 gRPC 连接参数。
@@ -50,6 +54,8 @@ type resolvedDependencies struct {
 	dial        DialOptions
 	factory     ClientFactory
 	credentials Credentials
+	schemas     *SchemaRegistry
+	schemaCF    SchemaClientFactory
 }
 
 func resolveDependencies(cfg Config, deps Dependencies) resolvedDependencies {
@@ -86,6 +92,11 @@ func resolveDependencies(cfg Config, deps Dependencies) resolvedDependencies {
 		factory = defaultClientFactory
 	}
 
+	schemaFactory := deps.SchemaClientFactory
+	if schemaFactory == nil {
+		schemaFactory = defaultSchemaClientFactory
+	}
+
 	creds := Credentials{
 		EmulatorEndpoint: cfg.EmulatorEndpoint,
 	}
@@ -103,10 +114,20 @@ func resolveDependencies(cfg Config, deps Dependencies) resolvedDependencies {
 		dial:        dial,
 		factory:     factory,
 		credentials: creds,
+		schemas:     deps.Schemas,
+		schemaCF:    schemaFactory,
 	}
 }
 
 func defaultClientFactory(ctx context.Context, projectID string, creds Credentials, dial DialOptions) (*pubsub.Client, error) {
+	return pubsub.NewClient(ctx, projectID, clientOptions(creds, dial)...)
+}
+
+func defaultSchemaClientFactory(ctx context.Context, projectID string, creds Credentials, dial DialOptions) (*pubsub.SchemaClient, error) {
+	return pubsub.NewSchemaClient(ctx, projectID, clientOptions(creds, dial)...)
+}
+
+func clientOptions(creds Credentials, dial DialOptions) []option.ClientOption {
 	opts := make([]option.ClientOption, 0, 4)
 
 	if creds.EmulatorEndpoint != "" {
@@ -125,8 +146,7 @@ func defaultClientFactory(ctx context.Context, projectID string, creds Credentia
 	if dial.Insecure && creds.EmulatorEndpoint == "" {
 		opts = append(opts, option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
 	}
-
-	return pubsub.NewClient(ctx, projectID, opts...)
+	return opts
 }
 
 func cloneBytes(src []byte) []byte {
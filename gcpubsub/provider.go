@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/go-kratos/kratos/v2/log"
@@ -12,11 +13,14 @@ import (
 
 // Component 聚合 gcpubsub 组件资源。
 type Component struct {
-	client     *pubsub.Client
-	publisher  Publisher
-	subscriber Subscriber
-	logger     *log.Helper
-	cfg        Config
+	client      *pubsub.Client
+	publisher   Publisher
+	subscriber  Subscriber
+	publishers  Publishers
+	subscribers Subscribers
+	router      *Router
+	logger      *log.Helper
+	cfg         Config
 }
 
 // NewComponent 构建 gcpubsub 组件。
@@ -30,8 +34,14 @@ func NewComponent(ctx context.Context, cfg Config, deps Dependencies) (*Componen
 		return nil, nil, errors.New("gcpubsub: projectID is required")
 	}
 
+	switch sanitized.Compression.Algorithm {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", errUnsupportedEncoding, sanitized.Compression.Algorithm)
+	}
+
 	resolved := resolveDependencies(sanitized, deps)
-	helper := log.NewHelper(resolved.logger)
+	helper := log.NewHelper(log.With(resolved.logger, "logger", "gcpubsub"))
 
 	telemetry := newTelemetry(resolved.meter, helper, sanitized.metricsEnabled())
 
@@ -40,29 +50,64 @@ func NewComponent(ctx context.Context, cfg Config, deps Dependencies) (*Componen
 		return nil, nil, fmt.Errorf("gcpubsub: create client: %w", err)
 	}
 
-	var topic *pubsub.Topic
-	if sanitized.TopicID != "" {
-		topic = client.Topic(sanitized.TopicID)
+	publishers := make(Publishers, len(sanitized.Topics))
+	for _, name := range sortedNames(sanitized.Topics) {
+		tc := sanitized.Topics[name]
+		if tc.TopicID == "" {
+			_ = client.Close()
+			return nil, nil, fmt.Errorf("gcpubsub: topic %q: topicID is required", name)
+		}
+		publishers[name] = newPublisher(client.Topic(tc.TopicID), telemetry, helper, sanitized, tc, resolved.clock, resolved.schemas)
 	}
 
-	var sub *pubsub.Subscription
-	if sanitized.SubscriptionID != "" {
-		sub = client.Subscription(sanitized.SubscriptionID)
+	for key, name := range sanitized.Routing.Routes {
+		if _, ok := publishers[name]; !ok {
+			_ = client.Close()
+			return nil, nil, fmt.Errorf("gcpubsub: route %q: %w: %q", key, ErrUnknownTopic, name)
+		}
+	}
+
+	subscribers := make(Subscribers, len(sanitized.Subscriptions))
+	for _, name := range sortedNames(sanitized.Subscriptions) {
+		sc := sanitized.Subscriptions[name]
+		if sc.SubscriptionID == "" {
+			_ = client.Close()
+			return nil, nil, fmt.Errorf("gcpubsub: subscription %q: subscriptionID is required", name)
+		}
+		subscribers[name] = newSubscriber(client.Subscription(sc.SubscriptionID), telemetry, helper, sanitized, sc, resolved.clock, resolved.schemas)
 	}
 
-	pub := newPublisher(topic, telemetry, helper, sanitized, resolved.clock)
-	subc := newSubscriber(sub, telemetry, helper, sanitized, resolved.clock)
+	if sanitized.Schema.CompareTopicSchemas && resolved.schemas != nil {
+		compareTopicSchemas(ctx, client, sanitized, resolved, helper)
+	}
 
 	component := &Component{
-		client:     client,
-		publisher:  pub,
-		subscriber: subc,
-		logger:     helper,
-		cfg:        sanitized,
+		client:      client,
+		publisher:   publishers.Get(sanitized.Routing.DefaultTopic),
+		subscriber:  subscribers.Get(defaultName(sanitized.Subscriptions)),
+		publishers:  publishers,
+		subscribers: subscribers,
+		router:      newRouter(publishers, sanitized.Routing),
+		logger:      helper,
+		cfg:         sanitized,
 	}
 
 	cleanup := func() {
-		_ = component.publisher.Flush(context.Background())
+		var wg sync.WaitGroup
+		for name, sub := range component.subscribers {
+			wg.Add(1)
+			go func(name string, sub Subscriber) {
+				defer wg.Done()
+				// 每个 Subscription 使用各自的 Receive.ShutdownTimeout（未单独配置时继承全局值）。
+				stopCtx, cancel := context.WithTimeout(context.Background(), sanitized.Subscriptions[name].Receive.ShutdownTimeout)
+				defer cancel()
+				if err := sub.Stop(stopCtx); err != nil && component.cfg.loggingEnabled() {
+					helper.Warnw("msg", "gcpubsub subscriber stop failed", "subscription", name, "error", err)
+				}
+			}(name, sub)
+		}
+		wg.Wait()
+		_ = component.router.Flush(context.Background())
 		if err := client.Close(); err != nil && component.cfg.loggingEnabled() {
 			helper.Warnw("msg", "gcpubsub client close failed", "error", err)
 		}
@@ -71,6 +116,31 @@ func NewComponent(ctx context.Context, cfg Config, deps Dependencies) (*Componen
 	return component, cleanup, nil
 }
 
+// compareTopicSchemas 在启动时比对 Topic 绑定的 schema 与本地校验器，不一致时仅告警。
+func compareTopicSchemas(ctx context.Context, client *pubsub.Client, cfg Config, resolved resolvedDependencies, helper *log.Helper) {
+	schemaClient, err := resolved.schemaCF(ctx, cfg.ProjectID, resolved.credentials, resolved.dial)
+	if err != nil {
+		helper.Warnw("msg", "gcpubsub schema client create failed", "error", err)
+		return
+	}
+	defer schemaClient.Close()
+
+	for _, name := range sortedNames(cfg.Topics) {
+		topicID := cfg.Topics[name].TopicID
+		local := resolved.schemas.forTopic(topicID, "")
+		if local == nil {
+			continue
+		}
+		mismatches, err := compareTopicSchema(ctx, client.Topic(topicID), schemaClient, local)
+		if err != nil {
+			helper.Warnw("msg", "gcpubsub topic schema fetch failed", "topic", topicID, "error", err)
+		}
+		for _, m := range mismatches {
+			helper.Warnw("msg", "gcpubsub topic schema mismatch", "topic", topicID, "detail", m)
+		}
+	}
+}
+
 // ProvidePublisher 暴露 Publisher。
 func ProvidePublisher(c *Component) Publisher {
 	if c == nil || c.publisher == nil {
@@ -87,5 +157,29 @@ func ProvideSubscriber(c *Component) Subscriber {
 	return c.subscriber
 }
 
+// ProvideRouter 暴露按属性路由的 Router。
+func ProvideRouter(c *Component) *Router {
+	if c == nil || c.router == nil {
+		return newRouter(Publishers{}, RoutingConfig{})
+	}
+	return c.router
+}
+
+// ProvidePublishers 暴露全部具名 Publisher。
+func ProvidePublishers(c *Component) Publishers {
+	if c == nil {
+		return Publishers{}
+	}
+	return c.publishers
+}
+
+// ProvideSubscribers 暴露全部具名 Subscriber。
+func ProvideSubscribers(c *Component) Subscribers {
+	if c == nil {
+		return Subscribers{}
+	}
+	return c.subscribers
+}
+
 // ProviderSet 用于 Wire 注入。
-var ProviderSet = wire.NewSet(NewComponent, ProvidePublisher, ProvideSubscriber)
+var ProviderSet = wire.NewSet(NewComponent, ProvidePublisher, ProvideSubscriber, ProvideRouter, ProvidePublishers, ProvideSubscribers)
@@ -38,24 +38,33 @@ type publisher struct {
 	topicName       string
 	clock           func() time.Time
 	publishTimeout  time.Duration
+	schemas         *SchemaRegistry
+	validateSchema  bool
+	maxBytes        int
+	compression     CompressionConfig
 
 	stopOnce sync.Once
 }
 
-func newPublisher(topic *pubsub.Topic, telem *telemetry, helper *log.Helper, cfg Config, clock func() time.Time) Publisher {
+func newPublisher(topic *pubsub.Topic, telem *telemetry, helper *log.Helper, cfg Config, tc TopicConfig, clock func() time.Time, schemas *SchemaRegistry) Publisher {
 	if topic == nil {
 		return noopPublisher{}
 	}
-	topic.EnableMessageOrdering = cfg.orderingEnabled()
+	ordering := tc.OrderingKeyEnabled == nil || *tc.OrderingKeyEnabled
+	topic.EnableMessageOrdering = ordering
 	return &publisher{
 		topic:           topic,
 		telemetry:       telem,
 		logger:          helper,
 		loggingEnabled:  cfg.loggingEnabled(),
-		orderingEnabled: cfg.orderingEnabled(),
-		topicName:       cfg.TopicID,
+		orderingEnabled: ordering,
+		topicName:       tc.TopicID,
 		clock:           clock,
-		publishTimeout:  cfg.PublishTimeout,
+		publishTimeout:  tc.PublishTimeout,
+		schemas:         schemas,
+		validateSchema:  schemas != nil && (cfg.Schema.ValidatePublish == nil || *cfg.Schema.ValidatePublish),
+		maxBytes:        cfg.MaxMessageBytes,
+		compression:     cfg.Compression,
 	}
 }
 
@@ -67,6 +76,19 @@ func (p *publisher) Publish(ctx context.Context, msg Message) (string, error) {
 		ctx = context.Background()
 	}
 
+	if p.validateSchema {
+		validator := p.schemas.forTopic(p.topicName, msg.Attributes[eventTypeAttribute])
+		if err := validateSchema(validator, schemaDirectionPublish, p.topicName, &msg); err != nil {
+			if p.telemetry != nil {
+				p.telemetry.recordSchemaViolation(ctx, schemaDirectionPublish, p.topicName, msg.Attributes[eventTypeAttribute])
+			}
+			if p.loggingEnabled {
+				p.logger.WithContext(ctx).Warnw("msg", "gcpubsub publish rejected by schema", "topic", p.topicName, "event_id", msg.EventID, "error", err)
+			}
+			return "", err
+		}
+	}
+
 	publishCtx := ctx
 	var cancel context.CancelFunc
 	if p.publishTimeout > 0 {
@@ -75,21 +97,36 @@ func (p *publisher) Publish(ctx context.Context, msg Message) (string, error) {
 	}
 
 	attributes := cloneAttributes(msg.Attributes)
+	data, attributes, err := p.maybeCompress(ctx, msg.Data, attributes)
+	if err != nil {
+		return "", err
+	}
 	pubsubMsg := &pubsub.Message{
-		Data:       msg.Data,
+		Data:       data,
 		Attributes: attributes,
 	}
 	if p.orderingEnabled {
 		pubsubMsg.OrderingKey = msg.OrderingKey
 	}
 
+	if size := messageSize(pubsubMsg.Data, pubsubMsg.Attributes, pubsubMsg.OrderingKey); p.maxBytes > 0 && size > p.maxBytes {
+		tooLarge := &MessageTooLargeError{Topic: p.topicName, Size: size, Limit: p.maxBytes}
+		if p.telemetry != nil {
+			p.telemetry.recordPublish(ctx, p.topicName, size, 0, tooLarge)
+		}
+		if p.loggingEnabled {
+			p.logger.WithContext(ctx).Warnw("msg", "gcpubsub publish rejected by size limit", "topic", p.topicName, "event_id", msg.EventID, "size", size, "limit", p.maxBytes)
+		}
+		return "", tooLarge
+	}
+
 	start := p.clock()
 	result := p.topic.Publish(publishCtx, pubsubMsg)
 	serverID, err := result.Get(publishCtx)
 	latency := time.Since(start)
 
 	if p.telemetry != nil {
-		p.telemetry.recordPublish(ctx, p.topicName, len(msg.Data), latency, err)
+		p.telemetry.recordPublish(ctx, p.topicName, len(pubsubMsg.Data), latency, err)
 	}
 
 	if p.loggingEnabled {
@@ -102,6 +139,29 @@ func (p *publisher) Publish(ctx context.Context, msg Message) (string, error) {
 	return serverID, nil
 }
 
+// maybeCompress 在载荷达到阈值时压缩并写入 content-encoding 属性；压缩无收益时保留原文。
+func (p *publisher) maybeCompress(ctx context.Context, data []byte, attributes map[string]string) ([]byte, map[string]string, error) {
+	algorithm := p.compression.Algorithm
+	if algorithm == CompressionNone || len(data) < p.compression.ThresholdBytes || attributes[AttrContentEncoding] != "" {
+		return data, attributes, nil
+	}
+	compressed, err := compressPayload(algorithm, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.telemetry != nil {
+		p.telemetry.recordCompression(ctx, p.topicName, algorithm, len(data), len(compressed))
+	}
+	if len(compressed) >= len(data) {
+		return data, attributes, nil
+	}
+	if attributes == nil {
+		attributes = make(map[string]string, 1)
+	}
+	attributes[AttrContentEncoding] = algorithm
+	return compressed, attributes, nil
+}
+
 func (p *publisher) Flush(context.Context) error {
 	if p.topic == nil {
 		return nil
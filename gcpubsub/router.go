@@ -0,0 +1,92 @@
+package gcpubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownTopic 表示按名称或路由找不到对应的 Topic。
+var ErrUnknownTopic = errors.New("gcpubsub: unknown topic")
+
+// ErrUnknownSubscription 表示按名称找不到对应的 Subscription。
+var ErrUnknownSubscription = errors.New("gcpubsub: unknown subscription")
+
+// Router 是按消息属性选择 Topic 的 Publisher 实现。
+type Router struct {
+	publishers   map[string]Publisher
+	routes       map[string]string
+	attribute    string
+	defaultTopic string
+}
+
+func newRouter(publishers map[string]Publisher, cfg RoutingConfig) *Router {
+	return &Router{
+		publishers:   publishers,
+		routes:       cfg.Routes,
+		attribute:    cfg.Attribute,
+		defaultTopic: cfg.DefaultTopic,
+	}
+}
+
+// Publish 根据路由属性选择 Topic 发布；未命中路由时使用默认 Topic。
+func (r *Router) Publish(ctx context.Context, msg Message) (string, error) {
+	name := r.Resolve(msg)
+	pub, ok := r.publishers[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q (route key %q)", ErrUnknownTopic, name, msg.Attributes[r.attribute])
+	}
+	return pub.Publish(ctx, msg)
+}
+
+// Flush 刷新全部 Topic，返回遇到的首个错误。
+func (r *Router) Flush(ctx context.Context) error {
+	var firstErr error
+	for _, name := range sortedNames(r.publishers) {
+		if err := r.publishers[name].Flush(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Resolve 返回消息将被路由到的 Topic 名称。
+func (r *Router) Resolve(msg Message) string {
+	if key := msg.Attributes[r.attribute]; key != "" {
+		if name, ok := r.routes[key]; ok {
+			return name
+		}
+	}
+	return r.defaultTopic
+}
+
+// Publisher 返回具名 Topic 的 Publisher。
+func (r *Router) Publisher(name string) (Publisher, error) {
+	pub, ok := r.publishers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTopic, name)
+	}
+	return pub, nil
+}
+
+// Publishers 是具名 Publisher 集合，便于 Wire 注入后按名称取用。
+type Publishers map[string]Publisher
+
+// Get 返回具名 Publisher，不存在时返回禁用的占位实现。
+func (p Publishers) Get(name string) Publisher {
+	if pub, ok := p[name]; ok {
+		return pub
+	}
+	return noopPublisher{}
+}
+
+// Subscribers 是具名 Subscriber 集合，便于 Wire 注入后按名称取用。
+type Subscribers map[string]Subscriber
+
+// Get 返回具名 Subscriber，不存在时返回禁用的占位实现。
+func (s Subscribers) Get(name string) Subscriber {
+	if sub, ok := s[name]; ok {
+		return sub
+	}
+	return noopSubscriber{}
+}
@@ -0,0 +1,284 @@
+package gcpubsub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ErrSchemaViolation 表示消息载荷不符合注册的 schema。
+var ErrSchemaViolation = errors.New("gcpubsub: schema violation")
+
+// SchemaKind 标识 schema 的定义类型。
+type SchemaKind string
+
+const (
+	SchemaKindProtobuf   SchemaKind = "protobuf"
+	SchemaKindJSONSchema SchemaKind = "json_schema"
+)
+
+// SchemaEncoding 标识载荷的编码方式，与 Pub/Sub Topic 的 schema encoding 对应。
+type SchemaEncoding string
+
+const (
+	SchemaEncodingBinary SchemaEncoding = "binary"
+	SchemaEncodingJSON   SchemaEncoding = "json"
+)
+
+// SchemaValidator 在本地校验消息载荷。
+type SchemaValidator interface {
+	Validate(data []byte) error
+	Kind() SchemaKind
+	Encoding() SchemaEncoding
+	// Name 返回 schema 描述的消息名称，用于与 Topic 绑定的 schema 比对。
+	Name() string
+}
+
+// SchemaError 描述一次 schema 校验失败，可通过 errors.Is(err, ErrSchemaViolation) 判断。
+type SchemaError struct {
+	Direction string
+	Target    string
+	EventType string
+	Err       error
+}
+
+func (e *SchemaError) Error() string {
+	if e.EventType != "" {
+		return fmt.Sprintf("gcpubsub: schema violation on %s %s (event_type=%s): %v", e.Direction, e.Target, e.EventType, e.Err)
+	}
+	return fmt.Sprintf("gcpubsub: schema violation on %s %s: %v", e.Direction, e.Target, e.Err)
+}
+
+func (e *SchemaError) Unwrap() []error {
+	return []error{ErrSchemaViolation, e.Err}
+}
+
+// protoValidator 基于 protobuf 描述符校验载荷。
+type protoValidator struct {
+	desc     protoreflect.MessageDescriptor
+	encoding SchemaEncoding
+}
+
+// NewProtoValidator 基于消息描述符构造校验器，encoding 为空时按二进制编码处理。
+func NewProtoValidator(desc protoreflect.MessageDescriptor, encoding SchemaEncoding) SchemaValidator {
+	if encoding == "" {
+		encoding = SchemaEncodingBinary
+	}
+	return &protoValidator{desc: desc, encoding: encoding}
+}
+
+func (v *protoValidator) Validate(data []byte) error {
+	msg := dynamicpb.NewMessage(v.desc)
+	if v.encoding == SchemaEncodingJSON {
+		if err := protojson.Unmarshal(data, msg); err != nil {
+			return fmt.Errorf("decode %s: %w", v.desc.FullName(), err)
+		}
+	} else {
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return fmt.Errorf("decode %s: %w", v.desc.FullName(), err)
+		}
+		if len(msg.GetUnknown()) > 0 {
+			return fmt.Errorf("decode %s: payload contains unknown fields", v.desc.FullName())
+		}
+	}
+	if err := proto.CheckInitialized(msg); err != nil {
+		return fmt.Errorf("check %s: %w", v.desc.FullName(), err)
+	}
+	return nil
+}
+
+func (v *protoValidator) Kind() SchemaKind         { return SchemaKindProtobuf }
+func (v *protoValidator) Encoding() SchemaEncoding { return v.encoding }
+func (v *protoValidator) Name() string             { return string(v.desc.Name()) }
+
+// jsonSchemaValidator 基于 JSON Schema 校验 JSON 载荷。
+type jsonSchemaValidator struct {
+	schema *jsonschema.Schema
+	name   string
+}
+
+// NewJSONSchemaValidator 编译 JSON Schema 文档并构造校验器。
+func NewJSONSchemaValidator(name string, schema []byte) (SchemaValidator, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("gcpubsub: parse json schema %s: %w", name, err)
+	}
+	loc := "mem://gcpubsub/" + name + ".json"
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(loc, doc); err != nil {
+		return nil, fmt.Errorf("gcpubsub: add json schema %s: %w", name, err)
+	}
+	compiled, err := compiler.Compile(loc)
+	if err != nil {
+		return nil, fmt.Errorf("gcpubsub: compile json schema %s: %w", name, err)
+	}
+	return &jsonSchemaValidator{schema: compiled, name: name}, nil
+}
+
+func (v *jsonSchemaValidator) Validate(data []byte) error {
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+	return v.schema.Validate(inst)
+}
+
+func (v *jsonSchemaValidator) Kind() SchemaKind         { return SchemaKindJSONSchema }
+func (v *jsonSchemaValidator) Encoding() SchemaEncoding { return SchemaEncodingJSON }
+func (v *jsonSchemaValidator) Name() string             { return v.name }
+
+// SchemaRegistry 按 Topic / Subscription / 事件类型登记校验器。
+//
+// 查找顺序：(目标, event_type) → (目标, 任意) → (任意, event_type)。
+type SchemaRegistry struct {
+	mu            sync.RWMutex
+	topics        map[schemaKey]SchemaValidator
+	subscriptions map[schemaKey]SchemaValidator
+	eventTypes    map[string]SchemaValidator
+}
+
+type schemaKey struct {
+	target    string
+	eventType string
+}
+
+// NewSchemaRegistry 创建空的 schema 注册表。
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		topics:        make(map[schemaKey]SchemaValidator),
+		subscriptions: make(map[schemaKey]SchemaValidator),
+		eventTypes:    make(map[string]SchemaValidator),
+	}
+}
+
+// RegisterTopic 为 Topic ID 登记发布端校验器；eventType 为空表示该 Topic 的所有消息。
+func (r *SchemaRegistry) RegisterTopic(topicID, eventType string, v SchemaValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.topics[schemaKey{target: topicID, eventType: eventType}] = v
+}
+
+// RegisterSubscription 为 Subscription ID 登记消费端校验器；eventType 为空表示该订阅的所有消息。
+func (r *SchemaRegistry) RegisterSubscription(subscriptionID, eventType string, v SchemaValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscriptions[schemaKey{target: subscriptionID, eventType: eventType}] = v
+}
+
+// RegisterEventType 登记对发布与消费两端均生效的事件类型校验器。
+func (r *SchemaRegistry) RegisterEventType(eventType string, v SchemaValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.eventTypes[eventType] = v
+}
+
+func (r *SchemaRegistry) forTopic(topicID, eventType string) SchemaValidator {
+	return r.lookup(r.topics, topicID, eventType)
+}
+
+func (r *SchemaRegistry) forSubscription(subscriptionID, eventType string) SchemaValidator {
+	return r.lookup(r.subscriptions, subscriptionID, eventType)
+}
+
+func (r *SchemaRegistry) lookup(scoped map[schemaKey]SchemaValidator, target, eventType string) SchemaValidator {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if eventType != "" {
+		if v, ok := scoped[schemaKey{target: target, eventType: eventType}]; ok {
+			return v
+		}
+	}
+	if v, ok := scoped[schemaKey{target: target}]; ok {
+		return v
+	}
+	if eventType != "" {
+		if v, ok := r.eventTypes[eventType]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+const (
+	schemaDirectionPublish = "publish"
+	schemaDirectionReceive = "receive"
+)
+
+// validateSchema 查找并执行校验器，未登记时直接放行。
+func validateSchema(v SchemaValidator, direction, target string, msg *Message) error {
+	if v == nil {
+		return nil
+	}
+	if err := v.Validate(msg.Data); err != nil {
+		return &SchemaError{
+			Direction: direction,
+			Target:    target,
+			EventType: msg.Attributes[eventTypeAttribute],
+			Err:       err,
+		}
+	}
+	return nil
+}
+
+// eventTypeAttribute 为查找事件类型 schema 所使用的消息属性。
+const eventTypeAttribute = "event_type"
+
+// SchemaClientFactory 创建 Pub/Sub Schema 客户端的函数签名。
+type SchemaClientFactory func(ctx context.Context, projectID string, creds Credentials, dial DialOptions) (*pubsub.SchemaClient, error)
+
+// compareTopicSchema 拉取 Topic 绑定的 schema 并与本地校验器比对，返回不一致描述。
+func compareTopicSchema(ctx context.Context, topic *pubsub.Topic, schemas *pubsub.SchemaClient, local SchemaValidator) ([]string, error) {
+	cfg, err := topic.Config(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get topic config: %w", err)
+	}
+	if cfg.SchemaSettings == nil || cfg.SchemaSettings.Schema == "" {
+		return []string{"topic has no attached schema"}, nil
+	}
+
+	var mismatches []string
+	remoteEncoding := SchemaEncodingBinary
+	if cfg.SchemaSettings.Encoding == pubsub.EncodingJSON {
+		remoteEncoding = SchemaEncodingJSON
+	}
+	if remoteEncoding != local.Encoding() {
+		mismatches = append(mismatches, fmt.Sprintf("encoding: topic=%s local=%s", remoteEncoding, local.Encoding()))
+	}
+
+	schemaID := cfg.SchemaSettings.Schema
+	if idx := strings.LastIndex(schemaID, "/"); idx >= 0 {
+		schemaID = schemaID[idx+1:]
+	}
+	remote, err := schemas.Schema(ctx, schemaID, pubsub.SchemaViewFull)
+	if err != nil {
+		return mismatches, fmt.Errorf("get schema %s: %w", schemaID, err)
+	}
+	switch {
+	case remote.Type == pubsub.SchemaAvro:
+		mismatches = append(mismatches, fmt.Sprintf("type: topic=avro local=%s", local.Kind()))
+	case remote.Type == pubsub.SchemaProtocolBuffer && local.Kind() != SchemaKindProtobuf:
+		mismatches = append(mismatches, fmt.Sprintf("type: topic=protobuf local=%s", local.Kind()))
+	case remote.Type == pubsub.SchemaProtocolBuffer && !definesMessage(remote.Definition, local.Name()):
+		mismatches = append(mismatches, fmt.Sprintf("message %s not found in topic schema %s", local.Name(), schemaID))
+	}
+	return mismatches, nil
+}
+
+func definesMessage(definition, name string) bool {
+	pattern := regexp.MustCompile(`\bmessage\s+` + regexp.QuoteMeta(name) + `\b`)
+	return pattern.MatchString(definition)
+}
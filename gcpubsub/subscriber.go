@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/pubsub"
@@ -13,10 +14,18 @@ import (
 // Subscriber 定义 StreamingPull 消费接口。
 type Subscriber interface {
 	Receive(ctx context.Context, handler func(context.Context, *Message) error) error
-	Stop()
+	ReceiveWithAck(ctx context.Context, handler AckHandlerFunc) error
+	// Stop 停止拉取新消息并等待在途处理器完成；ctx 到期后取消剩余处理器并 Nack 其消息。
+	Stop(ctx context.Context) error
 }
 
-var errSubscriberDisabled = errors.New("gcpubsub: subscriber disabled")
+// abortGracePeriod 为 Stop 超时取消处理器后等待其完成确认的上限。
+const abortGracePeriod = time.Second
+
+var (
+	errSubscriberDisabled = errors.New("gcpubsub: subscriber disabled")
+	errSubscriberStopped  = errors.New("gcpubsub: subscriber stopped")
+)
 
 type subscriber struct {
 	subscription   *pubsub.Subscription
@@ -25,45 +34,112 @@ type subscriber struct {
 	loggingEnabled bool
 	subName        string
 	clock          func() time.Time
+	exactlyOnce    bool
+	maxExtension   time.Duration
+	schemas        *SchemaRegistry
+	validateSchema bool
+	maxInflated    int
+
+	mu       sync.Mutex
+	stopped  bool
+	nextPull int
+	pulls    map[int]context.CancelFunc
+	inflight sync.WaitGroup
+
+	// abortCtx 在 Stop 超时后取消，用于中断仍在运行的处理器。
+	abortCtx context.Context
+	abort    context.CancelFunc
 }
 
-func newSubscriber(sub *pubsub.Subscription, telem *telemetry, helper *log.Helper, cfg Config, clock func() time.Time) Subscriber {
+func newSubscriber(sub *pubsub.Subscription, telem *telemetry, helper *log.Helper, cfg Config, sc SubscriptionConfig, clock func() time.Time, schemas *SchemaRegistry) Subscriber {
 	if sub == nil {
 		return noopSubscriber{}
 	}
-	sub.ReceiveSettings.NumGoroutines = cfg.Receive.NumGoroutines
-	sub.ReceiveSettings.MaxOutstandingMessages = cfg.Receive.MaxOutstandingMessages
-	sub.ReceiveSettings.MaxOutstandingBytes = cfg.Receive.MaxOutstandingBytes
-	sub.ReceiveSettings.MaxExtension = cfg.Receive.MaxExtension
-	sub.ReceiveSettings.MaxExtensionPeriod = cfg.Receive.MaxExtensionPeriod
+	sub.ReceiveSettings.NumGoroutines = sc.Receive.NumGoroutines
+	sub.ReceiveSettings.MaxOutstandingMessages = sc.Receive.MaxOutstandingMessages
+	sub.ReceiveSettings.MaxOutstandingBytes = sc.Receive.MaxOutstandingBytes
+	sub.ReceiveSettings.MaxExtension = sc.Receive.MaxExtension
+	sub.ReceiveSettings.MaxExtensionPeriod = sc.Receive.MaxExtensionPeriod
 
+	abortCtx, abort := context.WithCancel(context.Background())
 	return &subscriber{
 		subscription:   sub,
 		telemetry:      telem,
 		logger:         helper,
 		loggingEnabled: cfg.loggingEnabled(),
-		subName:        cfg.SubscriptionID,
+		subName:        sc.SubscriptionID,
 		clock:          clock,
+		exactlyOnce:    sc.ExactlyOnceDelivery != nil && *sc.ExactlyOnceDelivery,
+		maxExtension:   sc.Receive.MaxExtension,
+		schemas:        schemas,
+		validateSchema: schemas != nil && (cfg.Schema.ValidateReceive == nil || *cfg.Schema.ValidateReceive),
+		maxInflated:    cfg.Compression.MaxDecompressedBytes,
+		pulls:          make(map[int]context.CancelFunc),
+		abortCtx:       abortCtx,
+		abort:          abort,
 	}
 }
 
 func (s *subscriber) Receive(ctx context.Context, handler func(context.Context, *Message) error) error {
+	if handler == nil {
+		return s.ReceiveWithAck(ctx, nil)
+	}
+	return s.ReceiveWithAck(ctx, func(hCtx context.Context, msg *Message, _ AckHandle) error {
+		return handler(hCtx, msg)
+	})
+}
+
+// ReceiveWithAck 允许处理器通过 AckHandle 显式确认消息；未显式确认时按返回错误决定：
+// nil 或 ErrPermanent → Ack，ErrRetryAfter → 延迟 Nack，其它错误 → Nack。
+func (s *subscriber) ReceiveWithAck(ctx context.Context, handler AckHandlerFunc) error {
 	if s.subscription == nil {
 		return errSubscriberDisabled
 	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	return s.subscription.Receive(ctx, func(rCtx context.Context, m *pubsub.Message) {
+
+	pullCtx, pullID, err := s.beginPull(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.endPull(pullID)
+
+	return s.subscription.Receive(pullCtx, func(rCtx context.Context, m *pubsub.Message) {
+		if !s.acquireInflight() {
+			m.Nack()
+			return
+		}
+		defer s.inflight.Done()
+
+		// 处理器上下文与拉取上下文分离：Stop 停止拉取时不会中断在途处理器，
+		// 仅在调用方 ctx 取消或 Stop 超时后才取消。
+		hCtx, hCancel := context.WithCancel(context.WithoutCancel(rCtx))
+		defer hCancel()
+		stopCaller := context.AfterFunc(ctx, hCancel)
+		defer stopCaller()
+		stopAbort := context.AfterFunc(s.abortCtx, hCancel)
+		defer stopAbort()
+
 		wrapped := convertPubsubMessage(m)
 		start := s.clock()
-		handlerErr := s.invokeHandler(rCtx, handler, wrapped)
+		ack := newAckHandle(rCtx, m, s, start)
+		var handlerErr error
+		if prepErr := s.prepareMessage(rCtx, wrapped); prepErr != nil {
+			// 无法解压或不符合 schema 的消息不进入处理器：解压失败包装 ErrPermanent 按永久失败 Ack，
+			// schema 违规则 Nack，交由订阅的死信策略处理。
+			handlerErr = prepErr
+		} else {
+			handlerErr = s.invokeHandler(hCtx, handler, wrapped, ack)
+		}
 		handlerLatency := time.Since(start)
 
-		if handlerErr != nil {
-			m.Nack()
-		} else {
-			m.Ack()
+		if !ack.settled() {
+			if s.abortCtx.Err() != nil {
+				_ = ack.Nack()
+			} else {
+				s.settleByError(rCtx, ack, wrapped, handlerErr)
+			}
 		}
 
 		ackLatency := time.Since(start)
@@ -76,9 +152,106 @@ func (s *subscriber) Receive(ctx context.Context, handler func(context.Context,
 	})
 }
 
-func (s *subscriber) Stop() {}
+// Stop 停止所有 Receive 循环的拉取，等待在途处理器（含尚未触发的延迟 Nack）在 ctx 截止前完成；
+// 超时后取消剩余处理器的上下文并立即触发延迟 Nack，再最多等待 abortGracePeriod 让其完成确认。
+func (s *subscriber) Stop(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	s.mu.Lock()
+	s.stopped = true
+	for _, cancel := range s.pulls {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		select {
+		case <-drained:
+			return nil
+		default:
+		}
+		s.abort()
+		if s.loggingEnabled {
+			s.logger.WithContext(ctx).Warnw("msg", "gcpubsub stop deadline exceeded, in-flight handlers canceled", "subscription", s.subName, "error", ctx.Err())
+		}
+		// 给被取消的处理器一小段时间完成 Nack，避免调用方随后关闭 client 时确认请求仍在途。
+		timer := time.NewTimer(abortGracePeriod)
+		defer timer.Stop()
+		select {
+		case <-drained:
+		case <-timer.C:
+			if s.loggingEnabled {
+				s.logger.WithContext(ctx).Warnw("msg", "gcpubsub handlers still running after abort grace period", "subscription", s.subName)
+			}
+		}
+		return fmt.Errorf("gcpubsub: stop subscriber: %w", ctx.Err())
+	}
+}
+
+func (s *subscriber) beginPull(ctx context.Context) (context.Context, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return nil, 0, errSubscriberStopped
+	}
+	pullCtx, cancel := context.WithCancel(ctx)
+	id := s.nextPull
+	s.nextPull++
+	s.pulls[id] = cancel
+	return pullCtx, id, nil
+}
+
+func (s *subscriber) endPull(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.pulls[id]; ok {
+		cancel()
+		delete(s.pulls, id)
+	}
+}
+
+// acquireInflight 在未停止时登记在途处理器；停止后到达的消息直接 Nack。
+func (s *subscriber) acquireInflight() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return false
+	}
+	s.inflight.Add(1)
+	return true
+}
 
-func (s *subscriber) invokeHandler(ctx context.Context, handler func(context.Context, *Message) error, msg *Message) (err error) {
+// settleByError 根据处理器返回的错误类型执行默认确认策略。
+func (s *subscriber) settleByError(ctx context.Context, ack *ackHandle, msg *Message, handlerErr error) {
+	switch {
+	case handlerErr == nil:
+		_ = ack.Ack()
+	case errors.Is(handlerErr, ErrPermanent):
+		if s.loggingEnabled {
+			s.logger.WithContext(ctx).Errorw("msg", "gcpubsub permanent failure, message dropped", "subscription", s.subName, "message_id", msg.ID, "error", handlerErr)
+		}
+		_ = ack.Ack()
+	default:
+		if delay, ok := RetryAfter(handlerErr); ok {
+			ack.NackWithDelay(delay)
+			return
+		}
+		_ = ack.Nack()
+	}
+}
+
+func (s *subscriber) invokeHandler(ctx context.Context, handler AckHandlerFunc, msg *Message, ack AckHandle) (err error) {
 	if handler == nil {
 		return errors.New("gcpubsub: nil handler")
 	}
@@ -90,7 +263,54 @@ func (s *subscriber) invokeHandler(ctx context.Context, handler func(context.Con
 			}
 		}
 	}()
-	return handler(ctx, msg)
+	return handler(ctx, msg, ack)
+}
+
+// prepareMessage 按 content-encoding 透明解压载荷，再执行 schema 校验。
+// 只处理本库写入的 gzip/zstd；其他取值（如 identity 或业务自定义含义）原样交给 handler。
+func (s *subscriber) prepareMessage(ctx context.Context, msg *Message) error {
+	if encoding := msg.Attributes[AttrContentEncoding]; isCompressionEncoding(encoding) {
+		inflated, err := decompressPayload(encoding, msg.Data, s.maxInflated)
+		if err != nil {
+			// 损坏或超限的载荷重投后结果不变，按永久失败处理。
+			return fmt.Errorf("%w: %w", ErrPermanent, err)
+		}
+		msg.Data = inflated
+		delete(msg.Attributes, AttrContentEncoding)
+	}
+	return s.checkSchema(ctx, msg)
+}
+
+func (s *subscriber) checkSchema(ctx context.Context, msg *Message) error {
+	if !s.validateSchema {
+		return nil
+	}
+	eventType := msg.Attributes[eventTypeAttribute]
+	err := validateSchema(s.schemas.forSubscription(s.subName, eventType), schemaDirectionReceive, s.subName, msg)
+	if err == nil {
+		return nil
+	}
+	if s.telemetry != nil {
+		s.telemetry.recordSchemaViolation(ctx, schemaDirectionReceive, s.subName, eventType)
+	}
+	// 未经处理的数据不 Ack：Nack 后由订阅的重试与死信策略决定去向，载荷不会被静默丢弃。
+	return err
+}
+
+func (s *subscriber) recordAck(ctx context.Context, m *pubsub.Message, action string, err error) {
+	if s.telemetry != nil {
+		s.telemetry.recordAck(ctx, s.subName, action, s.exactlyOnce, err)
+	}
+	if !s.loggingEnabled {
+		return
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).Warnw("msg", "gcpubsub ack result failed", "subscription", s.subName, "message_id", m.ID, "action", action, "error", err)
+		return
+	}
+	if s.exactlyOnce {
+		s.logger.WithContext(ctx).Debugw("msg", "gcpubsub ack result confirmed", "subscription", s.subName, "message_id", m.ID, "action", action)
+	}
 }
 
 func (s *subscriber) logReceive(ctx context.Context, msg *Message, latency time.Duration, err error) {
@@ -138,4 +358,8 @@ func (noopSubscriber) Receive(_ context.Context, _ func(context.Context, *Messag
 	return errSubscriberDisabled
 }
 
-func (noopSubscriber) Stop() {}
+func (noopSubscriber) ReceiveWithAck(_ context.Context, _ AckHandlerFunc) error {
+	return errSubscriberDisabled
+}
+
+func (noopSubscriber) Stop(context.Context) error { return nil }
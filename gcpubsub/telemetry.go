@@ -14,6 +14,12 @@ const (
 	attrResultKey       = "pubsub.result"
 	attrSubscriptionKey = "pubsub.subscription"
 	attrAttemptKey      = "pubsub.delivery_attempt"
+	attrAckActionKey    = "pubsub.ack_action"
+	attrExactlyOnceKey  = "pubsub.exactly_once"
+	attrDirectionKey    = "pubsub.direction"
+	attrTargetKey       = "pubsub.target"
+	attrEventTypeKey    = "pubsub.event_type"
+	attrEncodingKey     = "pubsub.content_encoding"
 )
 
 var (
@@ -21,6 +27,12 @@ var (
 	attrResult       = attribute.Key(attrResultKey)
 	attrSubscription = attribute.Key(attrSubscriptionKey)
 	attrAttempt      = attribute.Key(attrAttemptKey)
+	attrAckAction    = attribute.Key(attrAckActionKey)
+	attrExactlyOnce  = attribute.Key(attrExactlyOnceKey)
+	attrDirection    = attribute.Key(attrDirectionKey)
+	attrTarget       = attribute.Key(attrTargetKey)
+	attrEventType    = attribute.Key(attrEventTypeKey)
+	attrEncoding     = attribute.Key(attrEncodingKey)
 )
 
 // telemetry 负责记录指标与结构化日志。
@@ -36,6 +48,9 @@ type telemetry struct {
 	handlerLatency metric.Float64Histogram
 	ackLatency     metric.Float64Histogram
 	deliveryCount  metric.Int64Counter
+	ackCount       metric.Int64Counter
+	schemaViolated metric.Int64Counter
+	compressRatio  metric.Float64Histogram
 }
 
 func newTelemetry(meter metric.Meter, helper *log.Helper, enabled bool) *telemetry {
@@ -66,6 +81,15 @@ func newTelemetry(meter metric.Meter, helper *log.Helper, enabled bool) *telemet
 	if t.deliveryCount, err = meter.Int64Counter("pubsub_delivery_attempt_total"); err != nil {
 		helper.Warnw("msg", "gcpubsub: register delivery_attempt", "err", err)
 	}
+	if t.ackCount, err = meter.Int64Counter("pubsub_ack_total"); err != nil {
+		helper.Warnw("msg", "gcpubsub: register ack_total", "err", err)
+	}
+	if t.schemaViolated, err = meter.Int64Counter("pubsub_schema_violation_total"); err != nil {
+		helper.Warnw("msg", "gcpubsub: register schema_violation_total", "err", err)
+	}
+	if t.compressRatio, err = meter.Float64Histogram("pubsub_compression_ratio"); err != nil {
+		helper.Warnw("msg", "gcpubsub: register compression_ratio", "err", err)
+	}
 	return t
 }
 
@@ -131,6 +155,58 @@ func (t *telemetry) RecordReceive(ctx context.Context, subscription string, hand
 	}
 }
 
+func (t *telemetry) recordAck(ctx context.Context, subscription string, action string, exactlyOnce bool, err error) {
+	t.RecordAck(ctx, subscription, action, exactlyOnce, err)
+}
+
+// RecordAck 暴露给测试的指标记录函数。
+func (t *telemetry) RecordAck(ctx context.Context, subscription string, action string, exactlyOnce bool, err error) {
+	if !t.enabled || t.ackCount == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	t.ackCount.Add(ctx, 1, metric.WithAttributes(
+		attrSubscription.String(subscription),
+		attrAckAction.String(action),
+		attrExactlyOnce.Bool(exactlyOnce),
+		attrResult.String(result),
+	))
+}
+
+func (t *telemetry) recordSchemaViolation(ctx context.Context, direction, target, eventType string) {
+	t.RecordSchemaViolation(ctx, direction, target, eventType)
+}
+
+// RecordSchemaViolation 暴露给测试的指标记录函数。
+func (t *telemetry) RecordSchemaViolation(ctx context.Context, direction, target, eventType string) {
+	if !t.enabled || t.schemaViolated == nil {
+		return
+	}
+	t.schemaViolated.Add(ctx, 1, metric.WithAttributes(
+		attrDirection.String(direction),
+		attrTarget.String(target),
+		attrEventType.String(eventType),
+	))
+}
+
+func (t *telemetry) recordCompression(ctx context.Context, topic, encoding string, originalBytes, compressedBytes int) {
+	t.RecordCompression(ctx, topic, encoding, originalBytes, compressedBytes)
+}
+
+// RecordCompression 暴露给测试的指标记录函数，记录压缩后与原始大小之比。
+func (t *telemetry) RecordCompression(ctx context.Context, topic, encoding string, originalBytes, compressedBytes int) {
+	if !t.enabled || t.compressRatio == nil || originalBytes <= 0 {
+		return
+	}
+	t.compressRatio.Record(ctx, float64(compressedBytes)/float64(originalBytes), metric.WithAttributes(
+		attrTopic.String(topic),
+		attrEncoding.String(encoding),
+	))
+}
+
 // NewTestTelemetry 供测试创建启用指标的 telemetry。
 func NewTelemetryForTest(meter metric.Meter, helper *log.Helper, enabled bool) *telemetry {
 	return newTelemetry(meter, helper, enabled)
@@ -234,6 +234,12 @@ func TestPublisherFlush(t *testing.T) {
 
 func setupComponent(t *testing.T, cfg gcpubsub.Config) (*gcpubsub.Component, context.Context) {
 	t.Helper()
+	return setupComponentWithDeps(t, cfg, nil)
+}
+
+// setupComponentWithDeps 与 setupComponent 相同，但允许在构造前调整 Dependencies（Meter、Schemas 等）。
+func setupComponentWithDeps(t *testing.T, cfg gcpubsub.Config, customize func(*gcpubsub.Dependencies)) (*gcpubsub.Component, context.Context) {
+	t.Helper()
 
 	srv := pstest.NewServer()
 	t.Cleanup(func() { _ = srv.Close() })
@@ -272,6 +278,9 @@ func setupComponent(t *testing.T, cfg gcpubsub.Config) (*gcpubsub.Component, con
 		ClientFactory: factory,
 		Dial:          gcpubsub.DialOptions{Insecure: true},
 	}
+	if customize != nil {
+		customize(&deps)
+	}
 
 	ctx := context.Background()
 	comp, cleanup, err := gcpubsub.NewComponent(ctx, cfg, deps)
@@ -0,0 +1,184 @@
+package gcpubsub_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bionicotaku/lingo-utils/gcpubsub"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestCompressedPayloadRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{gcpubsub.CompressionGzip, gcpubsub.CompressionZstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			cfg := gcpubsub.Config{
+				ProjectID:      "test-project",
+				TopicID:        "test-topic",
+				SubscriptionID: "test-sub",
+				Compression: gcpubsub.CompressionConfig{
+					Algorithm:      algorithm,
+					ThresholdBytes: 128,
+				},
+			}
+			comp, baseCtx := setupComponent(t, cfg)
+
+			payload := bytes.Repeat([]byte("lingo-utils "), 1024)
+			if _, err := comp.Publish(baseCtx, gcpubsub.Message{
+				Data:       payload,
+				Attributes: map[string]string{"k": "v"},
+			}); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+
+			recvCtx, cancel := context.WithTimeout(baseCtx, 5*time.Second)
+			defer cancel()
+
+			var got *gcpubsub.Message
+			err := comp.Receive(recvCtx, func(_ context.Context, msg *gcpubsub.Message) error {
+				got = msg
+				cancel()
+				return nil
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				t.Fatalf("receive: %v", err)
+			}
+			if got == nil {
+				t.Fatalf("message not received")
+			}
+			if !bytes.Equal(got.Data, payload) {
+				t.Fatalf("payload mismatch after decompression: %d bytes", len(got.Data))
+			}
+			if _, ok := got.Attributes[gcpubsub.AttrContentEncoding]; ok {
+				t.Fatalf("content-encoding attribute should be stripped before handler")
+			}
+			if got.Attributes["k"] != "v" {
+				t.Fatalf("unexpected attributes: %v", got.Attributes)
+			}
+		})
+	}
+}
+
+func TestOversizedDecompressionIsAckedPermanently(t *testing.T) {
+	for _, algorithm := range []string{gcpubsub.CompressionGzip, gcpubsub.CompressionZstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			reader := sdkmetric.NewManualReader()
+			provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+			t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+			cfg := gcpubsub.Config{
+				ProjectID:      "test-project",
+				TopicID:        "test-topic",
+				SubscriptionID: "test-sub",
+				Compression: gcpubsub.CompressionConfig{
+					Algorithm:            algorithm,
+					ThresholdBytes:       128,
+					MaxDecompressedBytes: 4096,
+				},
+			}
+			comp, baseCtx := setupComponentWithDeps(t, cfg, func(deps *gcpubsub.Dependencies) {
+				deps.Meter = provider.Meter("gcpubsub-test")
+			})
+
+			// 1 MiB 零字节压缩后只有几百字节，解压即超过 4 KiB 上限。
+			if _, err := comp.Publish(baseCtx, gcpubsub.Message{Data: make([]byte, 1<<20)}); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+
+			recvCtx, cancel := context.WithTimeout(baseCtx, 2*time.Second)
+			defer cancel()
+			var calls atomic.Int32
+			err := comp.Receive(recvCtx, func(context.Context, *gcpubsub.Message) error {
+				calls.Add(1)
+				return nil
+			})
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+				t.Fatalf("receive: %v", err)
+			}
+			if n := calls.Load(); n != 0 {
+				t.Fatalf("handler must not see oversized payload, calls=%d", n)
+			}
+			actions := ackActions(t, reader)
+			if actions["ack"] != 1 || actions["nack"] != 0 {
+				t.Fatalf("expected single ack without redelivery, got %v", actions)
+			}
+		})
+	}
+}
+
+func TestUnknownContentEncodingPassesThrough(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID:      "test-project",
+		TopicID:        "test-topic",
+		SubscriptionID: "test-sub",
+	}
+	comp, baseCtx := setupComponent(t, cfg)
+
+	payload := []byte(`{"hello":"world"}`)
+	if _, err := comp.Publish(baseCtx, gcpubsub.Message{
+		Data:       payload,
+		Attributes: map[string]string{gcpubsub.AttrContentEncoding: "identity"},
+	}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	recvCtx, cancel := context.WithTimeout(baseCtx, 5*time.Second)
+	defer cancel()
+
+	var got *gcpubsub.Message
+	err := comp.Receive(recvCtx, func(_ context.Context, msg *gcpubsub.Message) error {
+		got = msg
+		cancel()
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("receive: %v", err)
+	}
+	if got == nil {
+		t.Fatalf("message with content-encoding identity must reach handler")
+	}
+	if !bytes.Equal(got.Data, payload) {
+		t.Fatalf("payload should be untouched, got %q", got.Data)
+	}
+	if got.Attributes[gcpubsub.AttrContentEncoding] != "identity" {
+		t.Fatalf("foreign content-encoding should be preserved: %v", got.Attributes)
+	}
+}
+
+func TestPublishRejectsOversizedMessage(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID:       "test-project",
+		TopicID:         "test-topic",
+		MaxMessageBytes: 64,
+	}
+	comp, ctx := setupComponent(t, cfg)
+
+	_, err := comp.Publish(ctx, gcpubsub.Message{Data: bytes.Repeat([]byte{0x01}, 128)})
+	if !errors.Is(err, gcpubsub.ErrMessageTooLarge) {
+		t.Fatalf("expected message too large, got %v", err)
+	}
+	var tooLarge *gcpubsub.MessageTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Size != 128 || tooLarge.Limit != 64 {
+		t.Fatalf("unexpected error detail: %#v", tooLarge)
+	}
+}
+
+func TestCompressionAppliedBeforeSizeCheck(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID:       "test-project",
+		TopicID:         "test-topic",
+		MaxMessageBytes: 1024,
+		Compression: gcpubsub.CompressionConfig{
+			Algorithm:      gcpubsub.CompressionGzip,
+			ThresholdBytes: 512,
+		},
+	}
+	comp, ctx := setupComponent(t, cfg)
+
+	if _, err := comp.Publish(ctx, gcpubsub.Message{Data: bytes.Repeat([]byte("a"), 8192)}); err != nil {
+		t.Fatalf("compressible payload should fit after compression: %v", err)
+	}
+}
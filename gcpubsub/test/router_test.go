@@ -0,0 +1,271 @@
+package gcpubsub_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/bionicotaku/lingo-utils/gcpubsub"
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestConfigNormalizeFoldsLegacyIDs(t *testing.T) {
+	cfg := gcpubsub.Config{
+		TopicID:        "legacy-topic",
+		SubscriptionID: "legacy-sub",
+		PublishTimeout: 3 * time.Second,
+		Topics: map[string]gcpubsub.TopicConfig{
+			"audit": {TopicID: "audit-topic"},
+		},
+	}
+	normalized := cfg.Normalize()
+
+	if got := normalized.Topics[gcpubsub.DefaultName].TopicID; got != "legacy-topic" {
+		t.Fatalf("expected legacy topic folded into default, got %q", got)
+	}
+	if got := normalized.Topics["audit"].PublishTimeout; got != 3*time.Second {
+		t.Fatalf("expected audit topic to inherit publish timeout, got %v", got)
+	}
+	if got := normalized.Subscriptions[gcpubsub.DefaultName].SubscriptionID; got != "legacy-sub" {
+		t.Fatalf("expected legacy subscription folded into default, got %q", got)
+	}
+	if normalized.Routing.DefaultTopic != gcpubsub.DefaultName {
+		t.Fatalf("unexpected default topic: %q", normalized.Routing.DefaultTopic)
+	}
+	if normalized.Routing.Attribute != "event_type" {
+		t.Fatalf("unexpected route attribute: %q", normalized.Routing.Attribute)
+	}
+	if _, ok := cfg.Topics[gcpubsub.DefaultName]; ok {
+		t.Fatalf("normalize must not mutate the original topics map")
+	}
+}
+
+func TestConfigNormalizeMergesPartialReceiveOverride(t *testing.T) {
+	cfg := gcpubsub.Config{
+		Receive: gcpubsub.ReceiveConfig{
+			NumGoroutines:          2,
+			MaxOutstandingMessages: 50,
+			MaxExtension:           10 * time.Minute,
+		},
+		Subscriptions: map[string]gcpubsub.SubscriptionConfig{
+			"orders": {SubscriptionID: "orders-sub", Receive: gcpubsub.ReceiveConfig{NumGoroutines: 8}},
+		},
+	}
+	got := cfg.Normalize().Subscriptions["orders"].Receive
+
+	if got.NumGoroutines != 8 {
+		t.Fatalf("expected override goroutines = 8, got %d", got.NumGoroutines)
+	}
+	if got.MaxOutstandingMessages != 50 {
+		t.Fatalf("expected inherited max outstanding messages = 50, got %d", got.MaxOutstandingMessages)
+	}
+	if got.MaxExtension != 10*time.Minute {
+		t.Fatalf("expected inherited max extension = 10m, got %v", got.MaxExtension)
+	}
+	if got.MaxOutstandingBytes <= 0 || got.ShutdownTimeout <= 0 {
+		t.Fatalf("expected defaults for fields unset at both levels, got %+v", got)
+	}
+}
+
+func TestRouterPublishesByEventType(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID: "test-project",
+		Topics: map[string]gcpubsub.TopicConfig{
+			"orders":  {TopicID: "orders-topic"},
+			"billing": {TopicID: "billing-topic"},
+		},
+		Subscriptions: map[string]gcpubsub.SubscriptionConfig{
+			"orders":  {SubscriptionID: "orders-sub"},
+			"billing": {SubscriptionID: "billing-sub"},
+		},
+		Routing: gcpubsub.RoutingConfig{
+			DefaultTopic: "orders",
+			Routes:       map[string]string{"invoice.created": "billing"},
+		},
+	}
+	topicsBySub := map[string]string{"orders-sub": "orders-topic", "billing-sub": "billing-topic"}
+	comp, ctx := setupMultiComponent(t, cfg, topicsBySub)
+
+	router := comp.Router()
+	if _, err := router.Publish(ctx, gcpubsub.Message{
+		Data:       []byte("invoice"),
+		Attributes: map[string]string{"event_type": "invoice.created"},
+	}); err != nil {
+		t.Fatalf("publish invoice: %v", err)
+	}
+	if _, err := router.Publish(ctx, gcpubsub.Message{
+		Data:       []byte("order"),
+		Attributes: map[string]string{"event_type": "order.placed"},
+	}); err != nil {
+		t.Fatalf("publish order: %v", err)
+	}
+
+	expectSingle(t, ctx, comp, "billing", "invoice")
+	expectSingle(t, ctx, comp, "orders", "order")
+
+	if _, err := comp.Subscriber("missing"); !errors.Is(err, gcpubsub.ErrUnknownSubscription) {
+		t.Fatalf("expected unknown subscription error, got %v", err)
+	}
+	if _, err := comp.Publisher("missing"); !errors.Is(err, gcpubsub.ErrUnknownTopic) {
+		t.Fatalf("expected unknown topic error, got %v", err)
+	}
+}
+
+func TestNewComponentRejectsUnknownRouteTarget(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID: "test-project",
+		Topics: map[string]gcpubsub.TopicConfig{
+			"orders": {TopicID: "orders-topic"},
+		},
+		Routing: gcpubsub.RoutingConfig{
+			Routes: map[string]string{"invoice.created": "billing"},
+		},
+	}
+	srv := pstest.NewServer()
+	t.Cleanup(func() { _ = srv.Close() })
+	cfg.EmulatorEndpoint = srv.Addr
+
+	_, _, err := gcpubsub.NewComponent(context.Background(), cfg, gcpubsub.Dependencies{
+		Logger:        log.NewStdLogger(io.Discard),
+		ClientFactory: emulatorFactory(srv, nil),
+	})
+	if !errors.Is(err, gcpubsub.ErrUnknownTopic) {
+		t.Fatalf("expected unknown topic error, got %v", err)
+	}
+}
+
+func expectSingle(t *testing.T, ctx context.Context, comp *gcpubsub.Component, name string, payload string) {
+	t.Helper()
+
+	sub, err := comp.Subscriber(name)
+	if err != nil {
+		t.Fatalf("subscriber %s: %v", name, err)
+	}
+	recvCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var got string
+	err = sub.Receive(recvCtx, func(_ context.Context, msg *gcpubsub.Message) error {
+		got = string(msg.Data)
+		cancel()
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("receive %s: %v", name, err)
+	}
+	if got != payload {
+		t.Fatalf("subscription %s: expected %q, got %q", name, payload, got)
+	}
+}
+
+func setupMultiComponent(t *testing.T, cfg gcpubsub.Config, topicsBySub map[string]string) (*gcpubsub.Component, context.Context) {
+	t.Helper()
+
+	srv := pstest.NewServer()
+	t.Cleanup(func() { _ = srv.Close() })
+	cfg.EmulatorEndpoint = srv.Addr
+
+	deps := gcpubsub.Dependencies{
+		Logger:        log.NewStdLogger(io.Discard),
+		ClientFactory: emulatorFactory(srv, topicsBySub),
+	}
+
+	ctx := context.Background()
+	comp, cleanup, err := gcpubsub.NewComponent(ctx, cfg, deps)
+	if err != nil {
+		t.Fatalf("new component: %v", err)
+	}
+	t.Cleanup(cleanup)
+	return comp, ctx
+}
+
+// emulatorFactory 创建连接 pstest 的客户端，并按 topicsBySub 预建 Topic 与 Subscription。
+func emulatorFactory(srv *pstest.Server, topicsBySub map[string]string) gcpubsub.ClientFactory {
+	return func(ctx context.Context, projectID string, _ gcpubsub.Credentials, _ gcpubsub.DialOptions) (*pubsub.Client, error) {
+		client, err := pubsub.NewClient(ctx, projectID,
+			option.WithEndpoint(srv.Addr),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+		if err != nil {
+			return nil, err
+		}
+		created := map[string]*pubsub.Topic{}
+		for subID, topicID := range topicsBySub {
+			topic, ok := created[topicID]
+			if !ok {
+				if topic, err = client.CreateTopic(ctx, topicID); err != nil {
+					return nil, err
+				}
+				created[topicID] = topic
+			}
+			if _, err := client.CreateSubscription(ctx, subID, pubsub.SubscriptionConfig{Topic: topic}); err != nil {
+				return nil, err
+			}
+		}
+		return client, nil
+	}
+}
+
+func TestCleanupUsesPerSubscriptionShutdownTimeout(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID: "test-project",
+		Receive:   gcpubsub.ReceiveConfig{ShutdownTimeout: time.Minute},
+		Topics: map[string]gcpubsub.TopicConfig{
+			"orders": {TopicID: "orders-topic"},
+		},
+		Subscriptions: map[string]gcpubsub.SubscriptionConfig{
+			"fast": {SubscriptionID: "fast-sub", Receive: gcpubsub.ReceiveConfig{ShutdownTimeout: 100 * time.Millisecond}},
+		},
+	}
+	srv := pstest.NewServer()
+	t.Cleanup(func() { _ = srv.Close() })
+	cfg.EmulatorEndpoint = srv.Addr
+
+	ctx := context.Background()
+	comp, cleanup, err := gcpubsub.NewComponent(ctx, cfg, gcpubsub.Dependencies{
+		Logger:        log.NewStdLogger(io.Discard),
+		ClientFactory: emulatorFactory(srv, map[string]string{"fast-sub": "orders-topic"}),
+	})
+	if err != nil {
+		t.Fatalf("new component: %v", err)
+	}
+	if _, err := comp.Publish(ctx, gcpubsub.Message{Data: []byte("slow")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	sub, err := comp.Subscriber("fast")
+	if err != nil {
+		t.Fatalf("subscriber: %v", err)
+	}
+	started := make(chan struct{}, 1)
+	go func() {
+		_ = sub.Receive(ctx, func(hCtx context.Context, _ *gcpubsub.Message) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-hCtx.Done()
+			return hCtx.Err()
+		})
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("handler not started")
+	}
+
+	start := time.Now()
+	cleanup()
+	// 100ms 超时 + 取消后的确认宽限期，远小于全局的 1 分钟。
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("cleanup ignored per-subscription shutdown timeout, elapsed=%v", elapsed)
+	}
+}
@@ -0,0 +1,172 @@
+package gcpubsub_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/bionicotaku/lingo-utils/gcpubsub"
+	"github.com/go-kratos/kratos/v2/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const orderSchema = `{
+  "type": "object",
+  "required": ["order_id", "amount"],
+  "properties": {
+    "order_id": {"type": "string"},
+    "amount": {"type": "number", "minimum": 0}
+  }
+}`
+
+func TestJSONSchemaValidator(t *testing.T) {
+	v, err := gcpubsub.NewJSONSchemaValidator("order", []byte(orderSchema))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if err := v.Validate([]byte(`{"order_id":"o-1","amount":12.5}`)); err != nil {
+		t.Fatalf("expected valid payload, got %v", err)
+	}
+	if err := v.Validate([]byte(`{"order_id":"o-1","amount":-1}`)); err == nil {
+		t.Fatalf("expected negative amount to be rejected")
+	}
+	if err := v.Validate([]byte(`not-json`)); err == nil {
+		t.Fatalf("expected malformed json to be rejected")
+	}
+}
+
+func TestProtoValidator(t *testing.T) {
+	desc := (&timestamppb.Timestamp{}).ProtoReflect().Descriptor()
+
+	binary := gcpubsub.NewProtoValidator(desc, gcpubsub.SchemaEncodingBinary)
+	payload, err := proto.Marshal(&timestamppb.Timestamp{Seconds: 42})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := binary.Validate(payload); err != nil {
+		t.Fatalf("expected valid binary payload, got %v", err)
+	}
+	if err := binary.Validate([]byte{0xff, 0xff, 0xff}); err == nil {
+		t.Fatalf("expected garbage payload to be rejected")
+	}
+
+	jsonValidator := gcpubsub.NewProtoValidator(desc, gcpubsub.SchemaEncodingJSON)
+	if err := jsonValidator.Validate([]byte(`"2024-01-01T00:00:00Z"`)); err != nil {
+		t.Fatalf("expected valid json payload, got %v", err)
+	}
+	if err := jsonValidator.Validate([]byte(`{"unknown":1}`)); err == nil {
+		t.Fatalf("expected invalid json payload to be rejected")
+	}
+}
+
+func TestPublishRejectsSchemaViolation(t *testing.T) {
+	validator, err := gcpubsub.NewJSONSchemaValidator("order", []byte(orderSchema))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	registry := gcpubsub.NewSchemaRegistry()
+	registry.RegisterEventType("order.placed", validator)
+
+	srv := pstest.NewServer()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	cfg := gcpubsub.Config{
+		ProjectID:        "test-project",
+		TopicID:          "orders-topic",
+		SubscriptionID:   "orders-sub",
+		EmulatorEndpoint: srv.Addr,
+	}
+	comp, cleanup, err := gcpubsub.NewComponent(context.Background(), cfg, gcpubsub.Dependencies{
+		Logger:        log.NewStdLogger(io.Discard),
+		ClientFactory: emulatorFactory(srv, map[string]string{"orders-sub": "orders-topic"}),
+		Schemas:       registry,
+	})
+	if err != nil {
+		t.Fatalf("new component: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	ctx := context.Background()
+	_, err = comp.Publish(ctx, gcpubsub.Message{
+		Data:       []byte(`{"order_id":"o-1"}`),
+		Attributes: map[string]string{"event_type": "order.placed"},
+	})
+	if !errors.Is(err, gcpubsub.ErrSchemaViolation) {
+		t.Fatalf("expected schema violation, got %v", err)
+	}
+	var schemaErr *gcpubsub.SchemaError
+	if !errors.As(err, &schemaErr) || schemaErr.Direction != "publish" || schemaErr.EventType != "order.placed" {
+		t.Fatalf("unexpected schema error: %#v", schemaErr)
+	}
+
+	if _, err := comp.Publish(ctx, gcpubsub.Message{
+		Data:       []byte(`{"order_id":"o-1","amount":3}`),
+		Attributes: map[string]string{"event_type": "order.placed"},
+	}); err != nil {
+		t.Fatalf("valid payload rejected: %v", err)
+	}
+	if _, err := comp.Publish(ctx, gcpubsub.Message{
+		Data:       []byte(`opaque`),
+		Attributes: map[string]string{"event_type": "order.cancelled"},
+	}); err != nil {
+		t.Fatalf("unregistered event type must not be validated: %v", err)
+	}
+}
+
+func TestReceiveNacksSchemaViolation(t *testing.T) {
+	validator, err := gcpubsub.NewJSONSchemaValidator("order", []byte(orderSchema))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	registry := gcpubsub.NewSchemaRegistry()
+	registry.RegisterEventType("order.placed", validator)
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	skip := false
+	cfg := gcpubsub.Config{
+		ProjectID:      "test-project",
+		TopicID:        "orders-topic",
+		SubscriptionID: "orders-sub",
+		Schema:         gcpubsub.SchemaConfig{ValidatePublish: &skip},
+	}
+	comp, ctx := setupComponentWithDeps(t, cfg, func(deps *gcpubsub.Dependencies) {
+		deps.Schemas = registry
+		deps.Meter = provider.Meter("gcpubsub-test")
+	})
+
+	// 发布端关闭校验，模拟上游绕过 schema 写入的脏数据。
+	if _, err := comp.Publish(ctx, gcpubsub.Message{
+		Data:       []byte(`{"order_id":"o-1"}`),
+		Attributes: map[string]string{"event_type": "order.placed"},
+	}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	recvCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var calls atomic.Int32
+	err = comp.Receive(recvCtx, func(context.Context, *gcpubsub.Message) error {
+		calls.Add(1)
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		t.Fatalf("receive: %v", err)
+	}
+
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("handler must not see non-conforming payload, calls=%d", n)
+	}
+	actions := ackActions(t, reader)
+	if actions["ack"] != 0 || actions["nack"] == 0 {
+		t.Fatalf("schema violation must be nacked for dead-lettering, got %v", actions)
+	}
+}
@@ -0,0 +1,231 @@
+package gcpubsub_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bionicotaku/lingo-utils/gcpubsub"
+	"github.com/go-kratos/kratos/v2/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRetryAfterExtractsDelay(t *testing.T) {
+	err := fmt.Errorf("downstream busy: %w", gcpubsub.ErrRetryAfter(3*time.Second))
+	delay, ok := gcpubsub.RetryAfter(err)
+	if !ok {
+		t.Fatalf("expected retry-after error to be detected")
+	}
+	if delay != 3*time.Second {
+		t.Fatalf("unexpected delay: %v", delay)
+	}
+	if _, ok := gcpubsub.RetryAfter(errors.New("plain")); ok {
+		t.Fatalf("plain error must not be treated as retry-after")
+	}
+}
+
+func TestExplicitNackTriggersRedelivery(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID:      "test-project",
+		TopicID:        "test-topic",
+		SubscriptionID: "test-sub",
+	}
+	comp, baseCtx := setupComponent(t, cfg)
+
+	if _, err := comp.Publish(baseCtx, gcpubsub.Message{Data: []byte("hello")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	recvCtx, cancel := context.WithTimeout(baseCtx, 5*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	attempts := 0
+	handler := func(ctx context.Context, msg *gcpubsub.Message, ack gcpubsub.AckHandle) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			// 客户端收到消息后会异步发送回执续租；pstest 若先处理 Nack 再处理回执，
+			// 消息会被重新租出而不会立即重投，因此稍等再 Nack。
+			time.Sleep(100 * time.Millisecond)
+			// 显式 Nack 后返回 nil，不应被默认策略覆盖为 Ack。
+			if err := ack.Nack(); err != nil {
+				t.Errorf("nack: %v", err)
+			}
+			return nil
+		}
+		if err := ack.Ack(); err != nil {
+			t.Errorf("ack: %v", err)
+		}
+		cancel()
+		return nil
+	}
+
+	err := comp.ReceiveWithAck(recvCtx, handler)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("receive: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts < 2 {
+		t.Fatalf("expected redelivery after explicit nack, attempts=%d", attempts)
+	}
+}
+
+func TestCheckLeaseReportsRemainingBudget(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID:      "test-project",
+		TopicID:        "test-topic",
+		SubscriptionID: "test-sub",
+		Receive:        gcpubsub.ReceiveConfig{MaxExtension: time.Minute},
+	}
+	comp, baseCtx := setupComponent(t, cfg)
+
+	if _, err := comp.Publish(baseCtx, gcpubsub.Message{Data: []byte("hello")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	recvCtx, cancel := context.WithTimeout(baseCtx, 5*time.Second)
+	defer cancel()
+
+	var withinErr, beyondErr error
+	err := comp.ReceiveWithAck(recvCtx, func(_ context.Context, _ *gcpubsub.Message, ack gcpubsub.AckHandle) error {
+		withinErr = ack.CheckLease(time.Second)
+		beyondErr = ack.CheckLease(2 * time.Minute)
+		cancel()
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("receive: %v", err)
+	}
+	if withinErr != nil {
+		t.Fatalf("lease within budget should pass, got %v", withinErr)
+	}
+	if !errors.Is(beyondErr, gcpubsub.ErrLeaseExceeded) {
+		t.Fatalf("expected ErrLeaseExceeded, got %v", beyondErr)
+	}
+}
+
+func TestPermanentErrorAcksMessage(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID:      "test-project",
+		TopicID:        "test-topic",
+		SubscriptionID: "test-sub",
+	}
+	comp, baseCtx := setupComponent(t, cfg)
+
+	if _, err := comp.Publish(baseCtx, gcpubsub.Message{Data: []byte("bad")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	recvCtx, cancel := context.WithTimeout(baseCtx, 2*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	attempts := 0
+	handler := func(ctx context.Context, msg *gcpubsub.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return fmt.Errorf("decode: %w", gcpubsub.ErrPermanent)
+	}
+
+	err := comp.Receive(recvCtx, handler)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("receive: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 1 {
+		t.Fatalf("permanent failure must not be redelivered, attempts=%d", attempts)
+	}
+}
+
+func TestRetryAfterDelaysRedelivery(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID:      "test-project",
+		TopicID:        "test-topic",
+		SubscriptionID: "test-sub",
+	}
+	comp, baseCtx := setupComponent(t, cfg)
+
+	if _, err := comp.Publish(baseCtx, gcpubsub.Message{Data: []byte("later")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	recvCtx, cancel := context.WithTimeout(baseCtx, 5*time.Second)
+	defer cancel()
+
+	const delay = 300 * time.Millisecond
+	var mu sync.Mutex
+	var seen []time.Time
+	handler := func(ctx context.Context, msg *gcpubsub.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, time.Now())
+		if len(seen) == 1 {
+			return gcpubsub.ErrRetryAfter(delay)
+		}
+		cancel()
+		return nil
+	}
+
+	err := comp.Receive(recvCtx, handler)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("receive: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) < 2 {
+		t.Fatalf("expected redelivery, attempts=%d", len(seen))
+	}
+	if gap := seen[1].Sub(seen[0]); gap < delay {
+		t.Fatalf("redelivery happened too early: %v", gap)
+	}
+}
+
+func TestTelemetryAckMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	helper := log.NewHelper(log.NewStdLogger(io.Discard))
+
+	tel := gcpubsub.NewTelemetryForTest(provider.Meter("test"), helper, true)
+
+	ctx := context.Background()
+	tel.RecordAck(ctx, "subA", "ack", true, nil)
+	tel.RecordAck(ctx, "subA", "ack", true, errors.New("invalid ack id"))
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &data); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	results := map[string]int64{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || m.Name != "pubsub_ack_total" {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				attrs := attributeMap(dp.Attributes)
+				if !attrs["pubsub.exactly_once"].AsBool() {
+					t.Fatalf("expected exactly_once attribute")
+				}
+				results[attrs["pubsub.result"].AsString()] += dp.Value
+			}
+		}
+	}
+	if results["success"] != 1 || results["error"] != 1 {
+		t.Fatalf("unexpected ack results: %v", results)
+	}
+}
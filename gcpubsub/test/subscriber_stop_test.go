@@ -0,0 +1,187 @@
+package gcpubsub_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bionicotaku/lingo-utils/gcpubsub"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestStopDrainsInflightHandlers(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID:      "test-project",
+		TopicID:        "test-topic",
+		SubscriptionID: "test-sub",
+	}
+	comp, baseCtx := setupComponent(t, cfg)
+
+	if _, err := comp.Publish(baseCtx, gcpubsub.Message{Data: []byte("hello")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	started := make(chan struct{})
+	var completed, canceled atomic.Bool
+	handler := func(ctx context.Context, msg *gcpubsub.Message) error {
+		close(started)
+		select {
+		case <-time.After(200 * time.Millisecond):
+			completed.Store(true)
+		case <-ctx.Done():
+			canceled.Store(true)
+		}
+		return nil
+	}
+
+	recvDone := make(chan error, 1)
+	go func() { recvDone <- comp.Receive(baseCtx, handler) }()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("handler not started")
+	}
+
+	stopCtx, cancel := context.WithTimeout(baseCtx, 2*time.Second)
+	defer cancel()
+	if err := comp.StopSubscriber(stopCtx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if !completed.Load() || canceled.Load() {
+		t.Fatalf("expected handler to finish without cancellation, completed=%v canceled=%v", completed.Load(), canceled.Load())
+	}
+
+	select {
+	case err := <-recvDone:
+		if err != nil && !errors.Is(err, context.Canceled) {
+			t.Fatalf("receive: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("receive did not return after stop")
+	}
+
+	if err := comp.Receive(baseCtx, handler); err == nil {
+		t.Fatalf("expected receive to fail after stop")
+	}
+}
+
+func TestStopCancelsHandlersAfterDeadline(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID:      "test-project",
+		TopicID:        "test-topic",
+		SubscriptionID: "test-sub",
+	}
+	comp, baseCtx := setupComponent(t, cfg)
+
+	if _, err := comp.Publish(baseCtx, gcpubsub.Message{Data: []byte("slow")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	started := make(chan struct{})
+	var canceled atomic.Bool
+	handler := func(ctx context.Context, msg *gcpubsub.Message) error {
+		close(started)
+		<-ctx.Done()
+		canceled.Store(true)
+		return ctx.Err()
+	}
+
+	recvDone := make(chan error, 1)
+	go func() { recvDone <- comp.Receive(baseCtx, handler) }()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("handler not started")
+	}
+
+	stopCtx, cancel := context.WithTimeout(baseCtx, 100*time.Millisecond)
+	defer cancel()
+	err := comp.StopSubscriber(stopCtx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	select {
+	case <-recvDone:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("receive did not return after forced stop")
+	}
+	if !canceled.Load() {
+		t.Fatalf("expected handler context to be canceled")
+	}
+}
+
+func TestStopFlushesDelayedNack(t *testing.T) {
+	cfg := gcpubsub.Config{
+		ProjectID:      "test-project",
+		TopicID:        "test-topic",
+		SubscriptionID: "test-sub",
+	}
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	comp, baseCtx := setupComponentWithDeps(t, cfg, func(deps *gcpubsub.Dependencies) {
+		deps.Meter = provider.Meter("test")
+	})
+
+	if _, err := comp.Publish(baseCtx, gcpubsub.Message{Data: []byte("later")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	started := make(chan struct{}, 1)
+	handler := func(ctx context.Context, msg *gcpubsub.Message, ack gcpubsub.AckHandle) error {
+		ack.NackWithDelay(30 * time.Second)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+	go func() { _ = comp.ReceiveWithAck(baseCtx, handler) }()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("handler not started")
+	}
+
+	// 停止拉取后延迟 Nack 立即触发，Stop 需等到 Nack 完成再返回，而不是等满 30s 或提前返回。
+	stopCtx, cancel := context.WithTimeout(baseCtx, 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := comp.StopSubscriber(stopCtx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("stop waited for the full nack delay, elapsed=%v", elapsed)
+	}
+	if got := ackActions(t, reader)["nack"]; got != 1 {
+		t.Fatalf("expected delayed nack to be applied before stop returned, nack=%d", got)
+	}
+}
+
+// ackActions 汇总 pubsub_ack_total 按 action 的计数。
+func ackActions(t *testing.T, reader *sdkmetric.ManualReader) map[string]int64 {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	out := map[string]int64{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || m.Name != "pubsub_ack_total" {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				out[attributeMap(dp.Attributes)["pubsub.ack_action"].AsString()] += dp.Value
+			}
+		}
+	}
+	return out
+}
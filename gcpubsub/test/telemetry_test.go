@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/bionicotaku/lingo-utils/gclog"
 	g "github.com/bionicotaku/lingo-utils/gcpubsub"
 	"github.com/go-kratos/kratos/v2/log"
 	"go.opentelemetry.io/otel/attribute"
@@ -146,3 +148,35 @@ func attributeMap(set attribute.Set) map[string]attribute.Value {
 	}
 	return out
 }
+
+func TestLevelOverrideFiltersComponentLogs(t *testing.T) {
+	levels := gclog.NewLevelVar(log.LevelDebug)
+	levels.SetOverride("gcpubsub", log.LevelError)
+	logger, buf, err := gclog.NewTestLogger(gclog.WithService("test"), gclog.WithVersion("dev"), gclog.WithLevelVar(levels))
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+
+	cfg := g.Config{ProjectID: "test-project", TopicID: "test-topic", MaxMessageBytes: 64}
+	comp, ctx := setupComponentWithDeps(t, cfg, func(deps *g.Dependencies) {
+		deps.Logger = logger
+	})
+	publishOversized := func() {
+		if _, err := comp.Publish(ctx, g.Message{Data: make([]byte, 128)}); !errors.Is(err, g.ErrMessageTooLarge) {
+			t.Fatalf("expected message too large, got %v", err)
+		}
+	}
+
+	// 覆盖为 ERROR 时，size limit 的 WARN 日志应被过滤。
+	publishOversized()
+	if strings.Contains(buf.String(), "rejected by size limit") {
+		t.Fatalf("override should filter gcpubsub warn log, got %s", buf.String())
+	}
+
+	levels.RemoveOverride("gcpubsub")
+	publishOversized()
+	out := buf.String()
+	if !strings.Contains(out, "rejected by size limit") || !strings.Contains(out, `"logger":"gcpubsub"`) {
+		t.Fatalf("expected tagged gcpubsub warn log after removing override, got %s", out)
+	}
+}
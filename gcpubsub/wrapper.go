@@ -1,6 +1,9 @@
 package gcpubsub
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // Publish 是 Component 提供的便捷发布方法。
 func (c *Component) Publish(ctx context.Context, msg Message) (string, error) {
@@ -12,7 +15,40 @@ func (c *Component) Receive(ctx context.Context, handler func(context.Context, *
 	return c.subscriber.Receive(ctx, handler)
 }
 
+// ReceiveWithAck 是 Component 提供的显式确认消费方法。
+func (c *Component) ReceiveWithAck(ctx context.Context, handler AckHandlerFunc) error {
+	return c.subscriber.ReceiveWithAck(ctx, handler)
+}
+
 // FlushPublisher 触发发布端刷新并停止 Topic。
 func (c *Component) FlushPublisher(ctx context.Context) error {
 	return c.publisher.Flush(ctx)
 }
+
+// StopSubscriber 停止拉取并等待在途处理器完成，语义同 Subscriber.Stop。
+func (c *Component) StopSubscriber(ctx context.Context) error {
+	return c.subscriber.Stop(ctx)
+}
+
+// Publisher 按名称返回具名 Topic 的 Publisher。
+func (c *Component) Publisher(name string) (Publisher, error) {
+	pub, ok := c.publishers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTopic, name)
+	}
+	return pub, nil
+}
+
+// Subscriber 按名称返回具名 Subscription 的 Subscriber。
+func (c *Component) Subscriber(name string) (Subscriber, error) {
+	sub, ok := c.subscribers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSubscription, name)
+	}
+	return sub, nil
+}
+
+// Router 返回按属性选择 Topic 的 Router。
+func (c *Component) Router() *Router {
+	return c.router
+}
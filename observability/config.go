@@ -1,30 +1,40 @@
-// Package observability 提供统一的 OpenTelemetry tracing 与 metrics 初始化入口。
+// Package observability 提供统一的 OpenTelemetry tracing、metrics 与 logs 初始化入口。
 package observability
 
-import "time"
+import (
+	"crypto/tls"
+	"time"
+)
 
 // Common exporter identifiers.
 const (
 	ExporterOTLPgRPC = "otlp_grpc"
+	ExporterOTLPHTTP = "otlp_http"
 	ExporterStdout   = "stdout"
 )
 
-// ObservabilityConfig aggregates tracing and metrics configuration.
+// ObservabilityConfig aggregates tracing, metrics and logs configuration.
 //
 //revive:disable-next-line:exported
 type ObservabilityConfig struct {
 	Tracing          *TracingConfig
 	Metrics          *MetricsConfig
+	Logs             *LogsConfig
 	GlobalAttributes map[string]string
 }
 
-// TracingConfig controls tracer provider initialization.
+// TracingConfig controls tracer provider initialization. URLPath, full-URL endpoints,
+// CAFile and TLS apply to otlp_http; Compression ("gzip" or "none") applies to both OTLP exporters.
 type TracingConfig struct {
 	Enabled            bool
 	Exporter           string
 	Endpoint           string
+	URLPath            string
 	Headers            map[string]string
 	Insecure           bool
+	CAFile             string
+	TLS                *tls.Config
+	Compression        string
 	SamplingRatio      float64
 	ServiceName        string
 	ServiceVersion     string
@@ -37,13 +47,19 @@ type TracingConfig struct {
 	Required           bool
 }
 
-// MetricsConfig controls meter provider initialization.
+// MetricsConfig controls meter provider initialization. URLPath, full-URL endpoints,
+// CAFile and TLS apply to otlp_http; Compression ("gzip" or "none") applies to both OTLP exporters.
 type MetricsConfig struct {
 	Enabled             bool
 	Exporter            string
 	Endpoint            string
+	URLPath             string
 	Headers             map[string]string
 	Insecure            bool
+	CAFile              string
+	TLS                 *tls.Config
+	Compression         string
+	ExportTimeout       time.Duration
 	Interval            time.Duration
 	ResourceAttributes  map[string]string
 	DisableRuntimeStats bool
@@ -52,6 +68,20 @@ type MetricsConfig struct {
 	GRPCIncludeHealth   bool
 }
 
+// LogsConfig controls logger provider initialization. gclog tees entries into the
+// provider when gclog.Config.OTelLogs is enabled.
+type LogsConfig struct {
+	Enabled        bool
+	Exporter       string
+	Endpoint       string
+	Headers        map[string]string
+	Insecure       bool
+	ExportInterval time.Duration
+	ExportTimeout  time.Duration
+	MaxQueueSize   int
+	Required       bool
+}
+
 // sanitize prepares configuration with sensible defaults without mutating original instance.
 func (c ObservabilityConfig) sanitize() ObservabilityConfig {
 	cfg := c
@@ -92,9 +122,31 @@ func (c ObservabilityConfig) sanitize() ObservabilityConfig {
 		if mt.Interval <= 0 {
 			mt.Interval = 60 * time.Second
 		}
+		if mt.ExportTimeout <= 0 {
+			mt.ExportTimeout = 10 * time.Second
+		}
 		// bool fields retain caller-specified values; no defaults beyond zero.
 		cfg.Metrics = &mt
 	}
 
+	if cfg.Logs == nil {
+		cfg.Logs = &LogsConfig{}
+	} else {
+		lg := *cfg.Logs
+		if lg.Exporter == "" {
+			lg.Exporter = ExporterOTLPgRPC
+		}
+		if lg.ExportInterval <= 0 {
+			lg.ExportInterval = time.Second
+		}
+		if lg.ExportTimeout <= 0 {
+			lg.ExportTimeout = 30 * time.Second
+		}
+		if lg.MaxQueueSize <= 0 {
+			lg.MaxQueueSize = 2048
+		}
+		cfg.Logs = &lg
+	}
+
 	return cfg
 }
@@ -8,6 +8,7 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 
+	"github.com/bionicotaku/lingo-utils/observability/logs"
 	"github.com/bionicotaku/lingo-utils/observability/metrics"
 	"github.com/bionicotaku/lingo-utils/observability/tracing"
 )
@@ -84,7 +85,7 @@ func WithAttributes(attrs map[string]string) Option {
 	}
 }
 
-// Init sets up tracing and metrics providers according to the provided configuration.
+// Init sets up tracing, metrics and logs providers according to the provided configuration.
 func Init(ctx context.Context, cfg ObservabilityConfig, opts ...Option) (func(context.Context) error, error) {
 	if ctx == nil {
 		return nil, errors.New("nil context")
@@ -112,8 +113,12 @@ func Init(ctx context.Context, cfg ObservabilityConfig, opts ...Option) (func(co
 		traceCfg := tracing.Config{
 			Exporter:           cfg.Tracing.Exporter,
 			Endpoint:           cfg.Tracing.Endpoint,
+			URLPath:            cfg.Tracing.URLPath,
 			Headers:            cfg.Tracing.Headers,
 			Insecure:           cfg.Tracing.Insecure,
+			CAFile:             cfg.Tracing.CAFile,
+			TLS:                cfg.Tracing.TLS,
+			Compression:        cfg.Tracing.Compression,
 			SamplingRatio:      cfg.Tracing.SamplingRatio,
 			BatchTimeout:       cfg.Tracing.BatchTimeout,
 			ExportTimeout:      cfg.Tracing.ExportTimeout,
@@ -157,8 +162,13 @@ func Init(ctx context.Context, cfg ObservabilityConfig, opts ...Option) (func(co
 		metricCfg := metrics.Config{
 			Exporter:            cfg.Metrics.Exporter,
 			Endpoint:            cfg.Metrics.Endpoint,
+			URLPath:             cfg.Metrics.URLPath,
 			Headers:             cfg.Metrics.Headers,
 			Insecure:            cfg.Metrics.Insecure,
+			CAFile:              cfg.Metrics.CAFile,
+			TLS:                 cfg.Metrics.TLS,
+			Compression:         cfg.Metrics.Compression,
+			ExportTimeout:       cfg.Metrics.ExportTimeout,
 			Interval:            cfg.Metrics.Interval,
 			DisableRuntimeStats: cfg.Metrics.DisableRuntimeStats,
 			Required:            cfg.Metrics.Required,
@@ -182,6 +192,32 @@ func Init(ctx context.Context, cfg ObservabilityConfig, opts ...Option) (func(co
 		}
 	}
 
+	if cfg.Logs.Enabled {
+		logsCfg := logs.Config{
+			Exporter:       cfg.Logs.Exporter,
+			Endpoint:       cfg.Logs.Endpoint,
+			Headers:        cfg.Logs.Headers,
+			Insecure:       cfg.Logs.Insecure,
+			ExportInterval: cfg.Logs.ExportInterval,
+			ExportTimeout:  cfg.Logs.ExportTimeout,
+			MaxQueueSize:   cfg.Logs.MaxQueueSize,
+			Required:       cfg.Logs.Required,
+		}
+
+		shutdown, err := logs.Init(ctx, logsCfg,
+			logs.WithLogger(options.logger),
+			logs.WithResource(options.resource),
+		)
+		if err != nil {
+			if logsCfg.Required {
+				return nil, err
+			}
+			log.NewHelper(options.logger).Warnf("logs disabled due to initialization error: %v", err)
+		} else if shutdown != nil {
+			shutdowns = append(shutdowns, shutdown)
+		}
+	}
+
 	return func(ctx context.Context) error {
 		var result error
 		for i := len(shutdowns) - 1; i >= 0; i-- {
@@ -0,0 +1,48 @@
+package otlpexport
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+)
+
+// LoggedError 用于标记已经由 Logger 处理过的错误，避免在全局 error handler 重复输出。
+type LoggedError struct {
+	err error
+}
+
+func (e *LoggedError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Error()
+}
+
+func (e *LoggedError) Unwrap() error {
+	return e.err
+}
+
+type otelErrorHandler struct {
+	logger *Logger
+}
+
+// NewErrorHandler returns an otel.ErrorHandler that logs through logger and skips LoggedError.
+func NewErrorHandler(logger *Logger) otel.ErrorHandler {
+	return &otelErrorHandler{
+		logger: logger,
+	}
+}
+
+func (h *otelErrorHandler) Handle(err error) {
+	if err == nil {
+		return
+	}
+
+	var logged *LoggedError
+	if errors.As(err, &logged) {
+		// 已经输出过日志，避免重复。
+		return
+	}
+
+	h.logger.LogUnhandled(err)
+}
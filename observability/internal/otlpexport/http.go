@@ -0,0 +1,138 @@
+package otlpexport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"google.golang.org/grpc/codes"
+)
+
+// maxErrorBody 限制错误响应体读取长度，只用于日志。
+const maxErrorBody = 1 << 10
+
+// HTTPStatusError is returned for OTLP/HTTP responses with status >= 400.
+type HTTPStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	msg := fmt.Sprintf("otlp http export failed: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Retryable follows the OTLP/HTTP specification: 429, 502, 503 and 504 may be retried.
+func (e *HTTPStatusError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Code maps the HTTP status onto the gRPC code reported in exporter logs.
+func (e *HTTPStatusError) Code() codes.Code {
+	if e.StatusCode == http.StatusBadGateway {
+		return codes.Unavailable
+	}
+	return status.ToGRPCCode(e.StatusCode)
+}
+
+// NewHTTPClient returns the client handed to otlptracehttp/otlpmetrichttp via WithHTTPClient.
+// Error responses surface as *HTTPStatusError so Retry classifies them like gRPC statuses
+// instead of relying on the exporters' unexported retry errors. The exporters ignore
+// WithTLSClientConfig once WithHTTPClient is set, so tlsCfg is applied here instead;
+// nil keeps the system roots.
+func NewHTTPClient(timeout time.Duration, tlsCfg *tls.Config) *http.Client {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsCfg != nil {
+		base.TLSClientConfig = tlsCfg.Clone()
+	}
+	return &http.Client{
+		Transport: &statusTransport{base: base},
+		Timeout:   timeout,
+	}
+}
+
+// TLSConfig combines an explicit tls.Config with a PEM CA bundle. caFile replaces the
+// RootCAs of a clone of base; nil is returned when neither is set.
+func TLSConfig(base *tls.Config, caFile string) (*tls.Config, error) {
+	if caFile == "" {
+		return base, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ca file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("ca file contains no PEM certificates")
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if base != nil {
+		cfg = base.Clone()
+	}
+	cfg.RootCAs = pool
+	return cfg, nil
+}
+
+type statusTransport struct {
+	base http.RoundTripper
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode < http.StatusBadRequest {
+		return resp, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return nil, &HTTPStatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
+
+// parseRetryAfter accepts both delay-seconds and HTTP-date forms.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Gzip parses the Compression setting: "gzip" enables compression, "" and "none" disable it.
+func Gzip(compression string) (bool, error) {
+	switch compression {
+	case "", "none":
+		return false, nil
+	case "gzip":
+		return true, nil
+	}
+	return false, fmt.Errorf("unsupported compression %q", compression)
+}
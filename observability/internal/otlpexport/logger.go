@@ -1,4 +1,5 @@
-package tracing
+// Package otlpexport 汇集 tracing 与 metrics 共用的 OTLP exporter 逻辑：重试、诊断日志与 HTTP 客户端。
+package otlpexport
 
 import (
 	"sync"
@@ -9,8 +10,8 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// exporterLogger 负责将遥测导出相关的诊断信息统一输出到 Kratos logger。
-type exporterLogger struct {
+// Logger 负责将遥测导出相关的诊断信息统一输出到 Kratos logger。
+type Logger struct {
 	helper *log.Helper
 
 	mu           sync.Mutex
@@ -18,13 +19,14 @@ type exporterLogger struct {
 	lastGrpcCode codes.Code
 }
 
-func newExporterLogger(logger log.Logger) *exporterLogger {
-	return &exporterLogger{
+// NewLogger wraps logger for exporter diagnostics.
+func NewLogger(logger log.Logger) *Logger {
+	return &Logger{
 		helper: log.NewHelper(logger),
 	}
 }
 
-func (l *exporterLogger) logRetry(err error, attempt int, nextBackoff time.Duration, throttle time.Duration, code codes.Code) {
+func (l *Logger) LogRetry(err error, attempt int, nextBackoff time.Duration, throttle time.Duration, code codes.Code) {
 	l.mu.Lock()
 	l.consecutive = attempt
 	l.lastGrpcCode = code
@@ -46,7 +48,7 @@ func (l *exporterLogger) logRetry(err error, attempt int, nextBackoff time.Durat
 	l.helper.Warnw(fields...)
 }
 
-func (l *exporterLogger) logPermanentFailure(err error, attempt int, code codes.Code) {
+func (l *Logger) LogPermanentFailure(err error, attempt int, code codes.Code) {
 	l.mu.Lock()
 	l.consecutive = attempt
 	l.lastGrpcCode = code
@@ -60,7 +62,7 @@ func (l *exporterLogger) logPermanentFailure(err error, attempt int, code codes.
 	)
 }
 
-func (l *exporterLogger) logContextFailure(err error, attempt int) {
+func (l *Logger) LogContextFailure(err error, attempt int) {
 	l.mu.Lock()
 	l.consecutive = attempt
 	l.lastGrpcCode = codes.Canceled
@@ -73,7 +75,8 @@ func (l *exporterLogger) logContextFailure(err error, attempt int) {
 	)
 }
 
-func (l *exporterLogger) logRecovery(spanCount int, attempts int, elapsed time.Duration) {
+// LogRecovery 仅在之前出现过失败时输出恢复日志；countKey 如 span_count、metric_count。
+func (l *Logger) LogRecovery(countKey string, count int, attempts int, elapsed time.Duration) {
 	l.mu.Lock()
 	hadFailures := l.consecutive > 0
 	l.consecutive = 0
@@ -89,7 +92,7 @@ func (l *exporterLogger) logRecovery(spanCount int, attempts int, elapsed time.D
 		"msg", "otel exporter recovered",
 		"attempts", attempts,
 		"duration", elapsed,
-		"span_count", spanCount,
+		countKey, count,
 	}
 	if prevCode != codes.OK {
 		fields = append(fields, "last_grpc_code", prevCode.String())
@@ -98,7 +101,7 @@ func (l *exporterLogger) logRecovery(spanCount int, attempts int, elapsed time.D
 	l.helper.Infow(fields...)
 }
 
-func (l *exporterLogger) logUnhandled(err error) {
+func (l *Logger) LogUnhandled(err error) {
 	if err == nil {
 		return
 	}
@@ -0,0 +1,163 @@
+package otlpexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	errdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetrySettings controls the exponential backoff applied to failed exports.
+type RetrySettings struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsed      time.Duration
+}
+
+// DefaultRetrySettings mirrors the OTLP exporters' built-in retry defaults.
+func DefaultRetrySettings() RetrySettings {
+	return RetrySettings{
+		InitialInterval: 5 * time.Second,
+		MaxInterval:     30 * time.Second,
+		MaxElapsed:      time.Minute,
+	}
+}
+
+// Retry 以指数退避调用 upload，并在每次失败/恢复时输出结构化日志。
+// 调用方需禁用 exporter 内置重试，避免重复回退逻辑。countKey/count 用于恢复日志中的数据量字段。
+func Retry(ctx context.Context, settings RetrySettings, logger *Logger, countKey string, count int, upload func(context.Context) error) error {
+	attempt := 0
+	start := time.Now()
+
+	backoffSeq := backoff.NewExponentialBackOff()
+	backoffSeq.InitialInterval = settings.InitialInterval
+	backoffSeq.MaxInterval = settings.MaxInterval
+	backoffSeq.Multiplier = backoff.DefaultMultiplier
+	backoffSeq.RandomizationFactor = backoff.DefaultRandomizationFactor
+	backoffSeq.Reset()
+
+	for {
+		err := upload(ctx)
+		if err == nil {
+			logger.LogRecovery(countKey, count, attempt, time.Since(start))
+			return nil
+		}
+
+		attempt++
+
+		retryable, code, throttle := ClassifyError(err)
+		if !retryable {
+			logger.LogPermanentFailure(err, attempt, code)
+			return &LoggedError{err: err}
+		}
+
+		delay := nextDelay(backoffSeq, throttle)
+		if settings.MaxElapsed > 0 && time.Since(start)+delay > settings.MaxElapsed {
+			finalErr := fmt.Errorf("otel exporter max retry time would elapse: %w", err)
+			logger.LogPermanentFailure(finalErr, attempt, code)
+			return &LoggedError{err: finalErr}
+		}
+
+		logger.LogRetry(err, attempt, delay, throttle, code)
+
+		if err := waitWithContext(ctx, delay); err != nil {
+			finalErr := fmt.Errorf("otel exporter retry aborted: %w", err)
+			logger.LogContextFailure(finalErr, attempt)
+			return &LoggedError{err: finalErr}
+		}
+	}
+}
+
+// ClassifyError reports whether err is worth retrying, the gRPC code used in logs and any
+// server-requested throttle delay. OTLP/HTTP responses are mapped onto the same codes.
+func ClassifyError(err error) (retryable bool, code codes.Code, throttle time.Duration) {
+	if err == nil {
+		return false, codes.OK, 0
+	}
+	var httpErr *HTTPStatusError
+	if errors.As(err, &httpErr) {
+		return httpErr.Retryable(), httpErr.Code(), httpErr.RetryAfter
+	}
+	if s, ok := status.FromError(err); ok {
+		retryable, throttle = retryableStatus(s)
+		return retryable, s.Code(), throttle
+	}
+	// OTLP/HTTP 超时与建连失败（拒绝连接、DNS 等）等同 gRPC Unavailable；
+	// 其余 *url.Error（证书校验失败、非法 URL 等）重试无意义。
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true, codes.Unavailable, 0
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true, codes.Unavailable, 0
+	}
+	return false, codes.Unknown, 0
+}
+
+func retryableStatus(s *status.Status) (bool, time.Duration) {
+	switch s.Code() {
+	case codes.Canceled,
+		codes.DeadlineExceeded,
+		codes.Aborted,
+		codes.OutOfRange,
+		codes.Unavailable,
+		codes.DataLoss:
+		_, d := throttleDelay(s)
+		return true, d
+	case codes.ResourceExhausted:
+		retryable, d := throttleDelay(s)
+		if !retryable {
+			return true, 0
+		}
+		return true, d
+	}
+	return false, 0
+}
+
+func throttleDelay(s *status.Status) (bool, time.Duration) {
+	for _, detail := range s.Details() {
+		if t, ok := detail.(*errdetails.RetryInfo); ok {
+			return true, t.RetryDelay.AsDuration()
+		}
+	}
+	return false, 0
+}
+
+func nextDelay(seq *backoff.ExponentialBackOff, throttle time.Duration) time.Duration {
+	delay := seq.NextBackOff()
+	if delay == backoff.Stop {
+		delay = seq.MaxInterval
+	}
+	if throttle > delay {
+		return throttle
+	}
+	return delay
+}
+
+func waitWithContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		default:
+			return nil
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
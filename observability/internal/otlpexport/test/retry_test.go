@@ -0,0 +1,164 @@
+package otlpexport_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/bionicotaku/lingo-utils/observability/internal/otlpexport"
+)
+
+func postStatus(t *testing.T, code int, header http.Header) error {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte("collector says no\n"))
+	}))
+	defer srv.Close()
+
+	resp, err := otlpexport.NewHTTPClient(time.Second, nil).Post(srv.URL+"/v1/traces", "application/x-protobuf", http.NoBody)
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+	return err
+}
+
+func TestClassifyHTTPStatus(t *testing.T) {
+	err := postStatus(t, http.StatusServiceUnavailable, http.Header{"Retry-After": {"2"}})
+	require.Error(t, err)
+	var statusErr *otlpexport.HTTPStatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, "collector says no", statusErr.Body)
+
+	retryable, code, throttle := otlpexport.ClassifyError(err)
+	require.True(t, retryable)
+	require.Equal(t, codes.Unavailable, code)
+	require.Equal(t, 2*time.Second, throttle)
+
+	retryable, code, _ = otlpexport.ClassifyError(postStatus(t, http.StatusTooManyRequests, nil))
+	require.True(t, retryable)
+	require.Equal(t, codes.ResourceExhausted, code)
+
+	retryable, code, _ = otlpexport.ClassifyError(postStatus(t, http.StatusUnauthorized, nil))
+	require.False(t, retryable)
+	require.Equal(t, codes.Unauthenticated, code)
+}
+
+func TestHTTPClientPassesSuccess(t *testing.T) {
+	require.NoError(t, postStatus(t, http.StatusOK, nil))
+}
+
+func TestClassifyConnectionFailure(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	_, err := otlpexport.NewHTTPClient(time.Second, nil).Post(url, "application/x-protobuf", http.NoBody)
+	require.Error(t, err)
+	retryable, code, _ := otlpexport.ClassifyError(err)
+	require.True(t, retryable)
+	require.Equal(t, codes.Unavailable, code)
+}
+
+func TestClassifyClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { <-release }))
+	defer srv.Close()
+	defer close(release)
+
+	_, err := otlpexport.NewHTTPClient(50*time.Millisecond, nil).Post(srv.URL, "application/x-protobuf", http.NoBody)
+	require.Error(t, err)
+	retryable, code, _ := otlpexport.ClassifyError(err)
+	require.True(t, retryable)
+	require.Equal(t, codes.Unavailable, code)
+}
+
+func TestClassifyNonTransientURLErrors(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	// 自签证书未加入信任链：*url.Error 但不是超时或建连失败。
+	_, err := otlpexport.NewHTTPClient(time.Second, nil).Post(srv.URL, "application/x-protobuf", http.NoBody)
+	require.Error(t, err)
+	retryable, _, _ := otlpexport.ClassifyError(err)
+	require.False(t, retryable)
+
+	_, err = otlpexport.NewHTTPClient(time.Second, nil).Post("ftp://collector/v1/traces", "application/x-protobuf", http.NoBody)
+	require.Error(t, err)
+	retryable, _, _ = otlpexport.ClassifyError(err)
+	require.False(t, retryable)
+}
+
+func TestHTTPClientTrustsConfiguredCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	tlsCfg := srv.Client().Transport.(*http.Transport).TLSClientConfig
+	resp, err := otlpexport.NewHTTPClient(time.Second, tlsCfg).Post(srv.URL, "application/x-protobuf", http.NoBody)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+}
+
+func TestRetryRecoversAfterTransientFailures(t *testing.T) {
+	var buf bytes.Buffer
+	logger := otlpexport.NewLogger(log.NewStdLogger(&buf))
+	settings := otlpexport.RetrySettings{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, MaxElapsed: time.Second}
+
+	calls := 0
+	err := otlpexport.Retry(context.Background(), settings, logger, "metric_count", 7, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return &otlpexport.HTTPStatusError{StatusCode: http.StatusBadGateway}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+	require.Contains(t, buf.String(), "otel exporter retry scheduled")
+	require.Contains(t, buf.String(), "otel exporter recovered")
+	require.Contains(t, buf.String(), "metric_count=7")
+}
+
+func TestRetryStopsOnPermanentFailure(t *testing.T) {
+	var buf bytes.Buffer
+	logger := otlpexport.NewLogger(log.NewStdLogger(&buf))
+
+	calls := 0
+	err := otlpexport.Retry(context.Background(), otlpexport.DefaultRetrySettings(), logger, "span_count", 1, func(context.Context) error {
+		calls++
+		return &otlpexport.HTTPStatusError{StatusCode: http.StatusBadRequest}
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, calls)
+	var logged *otlpexport.LoggedError
+	require.True(t, errors.As(err, &logged))
+	require.Contains(t, buf.String(), "otel exporter permanent failure")
+	require.Contains(t, buf.String(), "InvalidArgument")
+}
+
+func TestGzip(t *testing.T) {
+	for _, v := range []string{"", "none"} {
+		gzip, err := otlpexport.Gzip(v)
+		require.NoError(t, err)
+		require.False(t, gzip)
+	}
+	gzip, err := otlpexport.Gzip("gzip")
+	require.NoError(t, err)
+	require.True(t, gzip)
+
+	_, err = otlpexport.Gzip("zstd")
+	require.Error(t, err)
+}
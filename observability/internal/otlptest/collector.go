@@ -0,0 +1,89 @@
+// Package otlptest 提供 OTLP/HTTP exporter 测试共用的假 collector，记录收到的请求并按需返回错误状态。
+package otlptest
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Request 是 collector 收到的一次导出请求摘要。
+type Request struct {
+	Path     string
+	Encoding string
+	Auth     string
+}
+
+// Collector 是基于 httptest 的 OTLP/HTTP 接收端，测试结束时自动关闭。
+type Collector struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	status int
+	reqs   []Request
+}
+
+// NewCollector 启动明文 HTTP collector，默认返回 200。
+func NewCollector(t testing.TB) *Collector {
+	c := &Collector{status: http.StatusOK}
+	c.Server = httptest.NewServer(http.HandlerFunc(c.handle))
+	t.Cleanup(c.Close)
+	return c
+}
+
+// NewTLSCollector 启动自签证书的 HTTPS collector，配合 CAFile 验证自定义 CA。
+func NewTLSCollector(t testing.TB) *Collector {
+	c := &Collector{status: http.StatusOK}
+	c.Server = httptest.NewTLSServer(http.HandlerFunc(c.handle))
+	t.Cleanup(c.Close)
+	return c
+}
+
+// RespondWith 指定后续请求的响应状态码。
+func (c *Collector) RespondWith(status int) {
+	c.mu.Lock()
+	c.status = status
+	c.mu.Unlock()
+}
+
+// Requests 返回已收到请求的快照。
+func (c *Collector) Requests() []Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Request(nil), c.reqs...)
+}
+
+// CAFile 把 TLS collector 的证书写成 PEM 文件并返回路径。
+func (c *Collector) CAFile(t testing.TB) string {
+	t.Helper()
+	cert := c.Certificate()
+	if cert == nil {
+		t.Fatal("otlptest: CAFile requires a TLS collector")
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("otlptest: write ca file: %v", err)
+	}
+	return path
+}
+
+func (c *Collector) handle(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	c.reqs = append(c.reqs, Request{
+		Path:     r.URL.Path,
+		Encoding: r.Header.Get("Content-Encoding"),
+		Auth:     r.Header.Get("Authorization"),
+	})
+	status := c.status
+	c.mu.Unlock()
+	if status >= http.StatusBadRequest {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	w.WriteHeader(status)
+}
@@ -0,0 +1,112 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Config holds logs specific settings.
+type Config struct {
+	Exporter       string
+	Endpoint       string
+	Headers        map[string]string
+	Insecure       bool
+	ExportInterval time.Duration
+	ExportTimeout  time.Duration
+	MaxQueueSize   int
+	Required       bool
+}
+
+// Init creates a logger provider and installs it globally (go.opentelemetry.io/otel/log/global).
+// gclog tees entries into it when its OTelLogs option is enabled.
+func Init(ctx context.Context, cfg Config, opts ...Option) (func(context.Context) error, error) {
+	if ctx == nil {
+		return nil, errors.New("nil context")
+	}
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.logger == nil {
+		return nil, errors.New("logs: logger is required (use logs.WithLogger)")
+	}
+	if options.resource == nil {
+		options.resource = resource.Default()
+	}
+
+	cfg = sanitizeConfig(cfg)
+
+	exp, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	helper := log.NewHelper(options.logger)
+
+	processor := sdklog.NewBatchProcessor(exp,
+		sdklog.WithExportInterval(cfg.ExportInterval),
+		sdklog.WithExportTimeout(cfg.ExportTimeout),
+		sdklog.WithMaxQueueSize(cfg.MaxQueueSize),
+	)
+	lp := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(processor),
+		sdklog.WithResource(options.resource),
+	)
+
+	global.SetLoggerProvider(lp)
+
+	helper.Infof("logs initialized exporter=%s endpoint=%s", cfg.Exporter, cfg.Endpoint)
+	return func(ctx context.Context) error {
+		helper.Info("shutting down logger provider")
+		return lp.Shutdown(ctx)
+	}, nil
+}
+
+func sanitizeConfig(cfg Config) Config {
+	if cfg.Exporter == "" {
+		cfg.Exporter = "otlp_grpc"
+	}
+	if cfg.ExportInterval <= 0 {
+		cfg.ExportInterval = time.Second
+	}
+	if cfg.ExportTimeout <= 0 {
+		cfg.ExportTimeout = 30 * time.Second
+	}
+	if cfg.MaxQueueSize <= 0 {
+		cfg.MaxQueueSize = 2048
+	}
+	return cfg
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdklog.Exporter, error) {
+	switch cfg.Exporter {
+	case "otlp_grpc":
+		var opts []otlploggrpc.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlploggrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlploggrpc.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlploggrpc.WithHeaders(cfg.Headers))
+		}
+		if cfg.ExportTimeout > 0 {
+			opts = append(opts, otlploggrpc.WithTimeout(cfg.ExportTimeout))
+		}
+		return otlploggrpc.New(ctx, opts...)
+	case "stdout":
+		return stdoutlog.New(stdoutlog.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unsupported logs exporter %q", cfg.Exporter)
+	}
+}
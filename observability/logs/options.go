@@ -0,0 +1,39 @@
+// Package logs 封装 OpenTelemetry LoggerProvider 的初始化逻辑。
+package logs
+
+import (
+	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Option configures logs initialization.
+type Option func(*options)
+
+type options struct {
+	logger   log.Logger
+	resource *resource.Resource
+}
+
+func defaultOptions() options {
+	return options{
+		logger: nil,
+	}
+}
+
+// WithLogger sets the logger used for diagnostic output.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithResource sets the resource used by the logger provider.
+func WithResource(res *resource.Resource) Option {
+	return func(o *options) {
+		if res != nil {
+			o.resource = res
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package logs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	logs "github.com/bionicotaku/lingo-utils/observability/logs"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/log/global"
+	nooplog "go.opentelemetry.io/otel/log/noop"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestInitStdoutInstallsGlobalProvider(t *testing.T) {
+	res, err := resource.New(context.Background())
+	require.NoError(t, err)
+
+	shutdown, err := logs.Init(context.Background(), logs.Config{Exporter: "stdout", ExportInterval: 10 * time.Millisecond},
+		logs.WithLogger(log.NewStdLogger(testWriter{t})),
+		logs.WithResource(res),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, shutdown)
+	t.Cleanup(func() { global.SetLoggerProvider(nooplog.NewLoggerProvider()) })
+
+	require.IsType(t, &sdklog.LoggerProvider{}, global.GetLoggerProvider())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, shutdown(ctx))
+}
+
+func TestInitOTLPDoesNotDial(t *testing.T) {
+	shutdown, err := logs.Init(context.Background(), logs.Config{Endpoint: "127.0.0.1:4317", Insecure: true},
+		logs.WithLogger(log.NewStdLogger(testWriter{t})),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { global.SetLoggerProvider(nooplog.NewLoggerProvider()) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = shutdown(ctx)
+}
+
+func TestInitErrors(t *testing.T) {
+	logger := log.NewStdLogger(testWriter{t})
+
+	_, err := logs.Init(context.Background(), logs.Config{Exporter: "unknown"}, logs.WithLogger(logger))
+	require.ErrorContains(t, err, "unsupported logs exporter")
+
+	_, err = logs.Init(context.Background(), logs.Config{Exporter: "stdout"})
+	require.ErrorContains(t, err, "logger is required")
+
+	_, err = logs.Init(nil, logs.Config{Exporter: "stdout"}, logs.WithLogger(logger))
+	require.ErrorContains(t, err, "nil context")
+}
+
+type testWriter struct {
+	t *testing.T
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Logf("%s", string(p))
+	return len(p), nil
+}
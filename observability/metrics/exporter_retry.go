@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/bionicotaku/lingo-utils/observability/internal/otlpexport"
+)
+
+// retryingExporter 为 OTLP metric exporter 增加与 tracing 一致的退避重试与诊断日志；
+// Temporality/Aggregation/ForceFlush/Shutdown 直接委托给内层 exporter。
+type retryingExporter struct {
+	metric.Exporter
+	logger   *otlpexport.Logger
+	settings otlpexport.RetrySettings
+}
+
+// newRetryingExporter 包装 exp；调用方需已禁用 exporter 的内置重试。
+func newRetryingExporter(exp metric.Exporter, settings otlpexport.RetrySettings, logger *otlpexport.Logger) metric.Exporter {
+	return &retryingExporter{
+		Exporter: exp,
+		logger:   logger,
+		settings: settings,
+	}
+}
+
+// Export implements metric.Exporter.
+func (e *retryingExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	return otlpexport.Retry(ctx, e.settings, e.logger, "metric_count", metricCount(rm), func(ctx context.Context) error {
+		return e.Exporter.Export(ctx, rm)
+	})
+}
+
+func metricCount(rm *metricdata.ResourceMetrics) int {
+	if rm == nil {
+		return 0
+	}
+	total := 0
+	for _, sm := range rm.ScopeMetrics {
+		total += len(sm.Metrics)
+	}
+	return total
+}
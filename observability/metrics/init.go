@@ -2,8 +2,10 @@ package metrics
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -13,15 +15,24 @@ import (
 	"go.opentelemetry.io/otel/sdk/resource"
 
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+
+	"github.com/bionicotaku/lingo-utils/observability/internal/otlpexport"
 )
 
-// Config holds metrics specific settings.
+// Config holds metrics specific settings. Exporter is otlp_grpc, otlp_http or stdout;
+// for otlp_http, Endpoint may be host:port or a full URL and URLPath overrides /v1/metrics.
 type Config struct {
 	Exporter            string
 	Endpoint            string
+	URLPath             string
 	Headers             map[string]string
 	Insecure            bool
+	CAFile              string
+	TLS                 *tls.Config
+	Compression         string
+	ExportTimeout       time.Duration
 	Interval            time.Duration
 	DisableRuntimeStats bool
 	ResourceAttributes  map[string]string
@@ -49,7 +60,7 @@ func Init(ctx context.Context, cfg Config, opts ...Option) (func(context.Context
 
 	cfg = sanitizeConfig(cfg)
 
-	exp, err := newExporter(ctx, cfg)
+	exp, err := newExporter(ctx, cfg, otlpexport.NewLogger(options.logger))
 	if err != nil {
 		return nil, err
 	}
@@ -87,12 +98,19 @@ func sanitizeConfig(cfg Config) Config {
 	if cfg.Interval <= 0 {
 		cfg.Interval = 60 * time.Second
 	}
+	if cfg.ExportTimeout <= 0 {
+		cfg.ExportTimeout = 10 * time.Second
+	}
 	return cfg
 }
 
-func newExporter(ctx context.Context, cfg Config) (metric.Exporter, error) {
+func newExporter(ctx context.Context, cfg Config, logger *otlpexport.Logger) (metric.Exporter, error) {
 	switch cfg.Exporter {
 	case "otlp_grpc":
+		gzip, err := otlpexport.Gzip(cfg.Compression)
+		if err != nil {
+			return nil, err
+		}
 		var opts []otlpmetricgrpc.Option
 		if cfg.Endpoint != "" {
 			opts = append(opts, otlpmetricgrpc.WithEndpoint(cfg.Endpoint))
@@ -103,7 +121,54 @@ func newExporter(ctx context.Context, cfg Config) (metric.Exporter, error) {
 		if len(cfg.Headers) > 0 {
 			opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.Headers))
 		}
-		return otlpmetricgrpc.New(ctx, opts...)
+		if gzip {
+			opts = append(opts, otlpmetricgrpc.WithCompressor("gzip"))
+		}
+		opts = append(opts,
+			otlpmetricgrpc.WithTimeout(cfg.ExportTimeout),
+			otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig{Enabled: false}),
+		)
+		exp, err := otlpmetricgrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return newRetryingExporter(exp, otlpexport.DefaultRetrySettings(), logger), nil
+	case "otlp_http":
+		gzip, err := otlpexport.Gzip(cfg.Compression)
+		if err != nil {
+			return nil, err
+		}
+		var opts []otlpmetrichttp.Option
+		if strings.Contains(cfg.Endpoint, "://") {
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(cfg.Endpoint))
+		} else if cfg.Endpoint != "" {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.URLPath != "" {
+			opts = append(opts, otlpmetrichttp.WithURLPath(cfg.URLPath))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlpmetrichttp.WithHeaders(cfg.Headers))
+		}
+		if gzip {
+			opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+		}
+		tlsCfg, err := otlpexport.TLSConfig(cfg.TLS, cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts,
+			otlpmetrichttp.WithHTTPClient(otlpexport.NewHTTPClient(cfg.ExportTimeout, tlsCfg)),
+			otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig{Enabled: false}),
+		)
+		exp, err := otlpmetrichttp.New(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return newRetryingExporter(exp, otlpexport.DefaultRetrySettings(), logger), nil
 	case "stdout":
 		return stdoutmetric.New(stdoutmetric.WithPrettyPrint())
 	default:
@@ -0,0 +1,76 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	noopmetric "go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/bionicotaku/lingo-utils/observability/internal/otlptest"
+	metrics "github.com/bionicotaku/lingo-utils/observability/metrics"
+)
+
+func TestInitOTLPHTTP(t *testing.T) {
+	cases := []struct {
+		name    string
+		tls     bool
+		status  int
+		wantErr []string
+	}{
+		{name: "plaintext", status: http.StatusOK},
+		{name: "custom ca", tls: true, status: http.StatusOK},
+		{name: "permanent failure", status: http.StatusUnauthorized, wantErr: []string{"otel exporter permanent failure", "Unauthenticated"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := metrics.Config{
+				Exporter:            "otlp_http",
+				Compression:         "gzip",
+				Interval:            time.Hour,
+				DisableRuntimeStats: true,
+			}
+			var collector *otlptest.Collector
+			if tc.tls {
+				collector = otlptest.NewTLSCollector(t)
+				cfg.Endpoint = strings.TrimPrefix(collector.URL, "https://")
+				cfg.CAFile = collector.CAFile(t)
+			} else {
+				collector = otlptest.NewCollector(t)
+				cfg.Endpoint = strings.TrimPrefix(collector.URL, "http://")
+				cfg.Insecure = true
+			}
+			collector.RespondWith(tc.status)
+			t.Cleanup(func() { otel.SetMeterProvider(noopmetric.NewMeterProvider()) })
+
+			var buf strings.Builder
+			shutdown, err := metrics.Init(context.Background(), cfg, metrics.WithLogger(log.NewStdLogger(&buf)))
+			require.NoError(t, err)
+
+			counter, err := otel.Meter("test").Int64Counter("requests")
+			require.NoError(t, err)
+			counter.Add(context.Background(), 1)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if len(tc.wantErr) > 0 {
+				require.Error(t, shutdown(ctx))
+				for _, want := range tc.wantErr {
+					require.Contains(t, buf.String(), want)
+				}
+				return
+			}
+			require.NoError(t, shutdown(ctx))
+
+			reqs := collector.Requests()
+			require.Len(t, reqs, 1)
+			require.Equal(t, "/v1/metrics", reqs[0].Path)
+			require.Equal(t, "gzip", reqs[0].Encoding)
+		})
+	}
+}
@@ -23,7 +23,7 @@ type Component struct {
 	logger   log.Logger
 }
 
-// NewComponent installs tracing and metrics providers according to cfg. The
+// NewComponent installs tracing, metrics and logs providers according to cfg. The
 // returned cleanup function applies a bounded timeout to flush buffered data.
 func NewComponent(ctx context.Context, cfg ObservabilityConfig, info ServiceInfo, logger log.Logger) (*Component, func(), error) {
 	if ctx == nil {
@@ -267,6 +267,21 @@ func TestConfigSanitize_MetricsDefaults(t *testing.T) {
 				require.Equal(t, 30*time.Second, output.Interval)
 			},
 		},
+		{
+			name: "ExportTimeout 默认值，HTTP 字段原样保留",
+			input: &obs.MetricsConfig{
+				Enabled:     true,
+				Exporter:    obs.ExporterOTLPHTTP,
+				URLPath:     "/otlp/v1/metrics",
+				Compression: "gzip",
+			},
+			validate: func(t *testing.T, output *obs.MetricsConfig) {
+				require.Equal(t, 10*time.Second, output.ExportTimeout)
+				require.Equal(t, obs.ExporterOTLPHTTP, output.Exporter)
+				require.Equal(t, "/otlp/v1/metrics", output.URLPath)
+				require.Equal(t, "gzip", output.Compression)
+			},
+		},
 		{
 			name: "布尔字段应该保留用户指定的值",
 			input: &obs.MetricsConfig{
@@ -379,6 +394,9 @@ func invokeSanitize(cfg obs.ObservabilityConfig) obs.ObservabilityConfig {
 		if mt.Interval <= 0 {
 			mt.Interval = 60 * time.Second
 		}
+		if mt.ExportTimeout <= 0 {
+			mt.ExportTimeout = 10 * time.Second
+		}
 		sanitized.Metrics = &mt
 	}
 
@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log/global"
+	nooplog "go.opentelemetry.io/otel/log/noop"
 	noopmetric "go.opentelemetry.io/otel/metric/noop"
 	nooptrace "go.opentelemetry.io/otel/trace/noop"
 )
@@ -31,6 +33,10 @@ func TestInitWithStdoutExporters(t *testing.T) {
 			Interval:            50 * time.Millisecond,
 			DisableRuntimeStats: true,
 		},
+		Logs: &obs.LogsConfig{
+			Enabled:  true,
+			Exporter: obs.ExporterStdout,
+		},
 		GlobalAttributes: map[string]string{"global.attr": "value"},
 	}
 
@@ -50,6 +56,7 @@ func TestInitWithStdoutExporters(t *testing.T) {
 		}
 		otel.SetTracerProvider(nooptrace.NewTracerProvider())
 		otel.SetMeterProvider(noopmetric.NewMeterProvider())
+		global.SetLoggerProvider(nooplog.NewLoggerProvider())
 	})
 }
 
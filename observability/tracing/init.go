@@ -2,25 +2,36 @@ package tracing
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/bionicotaku/lingo-utils/observability/internal/otlpexport"
 )
 
-// Config holds tracing specific settings.
+// Config holds tracing specific settings. Exporter is otlp_grpc, otlp_http or stdout;
+// for otlp_http, Endpoint may be host:port or a full URL, URLPath overrides /v1/traces and
+// CAFile/TLS configure the HTTPS transport.
 type Config struct {
 	Exporter           string
 	Endpoint           string
+	URLPath            string
 	Headers            map[string]string
 	Insecure           bool
+	CAFile             string
+	TLS                *tls.Config
+	Compression        string
 	SamplingRatio      float64
 	BatchTimeout       time.Duration
 	ExportTimeout      time.Duration
@@ -55,7 +66,7 @@ func Init(ctx context.Context, cfg Config, opts ...Option) (func(context.Context
 
 	cfg = sanitizeConfig(cfg)
 
-	telemetryLogger := newExporterLogger(options.logger)
+	telemetryLogger := otlpexport.NewLogger(options.logger)
 	exporter, err := newExporter(ctx, cfg, telemetryLogger)
 	if err != nil {
 		return nil, err
@@ -63,7 +74,7 @@ func Init(ctx context.Context, cfg Config, opts ...Option) (func(context.Context
 
 	helper := log.NewHelper(options.logger)
 	prevHandler := otel.GetErrorHandler()
-	handler := newErrorHandler(telemetryLogger)
+	handler := otlpexport.NewErrorHandler(telemetryLogger)
 	otel.SetErrorHandler(handler)
 
 	batcher := sdktrace.WithBatcher(exporter,
@@ -115,9 +126,13 @@ func sanitizeConfig(cfg Config) Config {
 	return cfg
 }
 
-func newExporter(ctx context.Context, cfg Config, logger *exporterLogger) (sdktrace.SpanExporter, error) {
+func newExporter(ctx context.Context, cfg Config, logger *otlpexport.Logger) (sdktrace.SpanExporter, error) {
 	switch cfg.Exporter {
 	case "otlp_grpc":
+		gzip, err := otlpexport.Gzip(cfg.Compression)
+		if err != nil {
+			return nil, err
+		}
 		var clientOpts []otlptracegrpc.Option
 		if cfg.Endpoint != "" {
 			clientOpts = append(clientOpts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
@@ -131,7 +146,44 @@ func newExporter(ctx context.Context, cfg Config, logger *exporterLogger) (sdktr
 		if cfg.ExportTimeout > 0 {
 			clientOpts = append(clientOpts, otlptracegrpc.WithTimeout(cfg.ExportTimeout))
 		}
-		return newRetryingExporter(ctx, defaultRetrySettings(), logger, clientOpts...)
+		if gzip {
+			clientOpts = append(clientOpts, otlptracegrpc.WithCompressor("gzip"))
+		}
+		// 禁用内置重试，避免重复回退逻辑。
+		clientOpts = append(clientOpts, otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{Enabled: false}))
+		return newRetryingExporter(ctx, otlptracegrpc.NewClient(clientOpts...), otlpexport.DefaultRetrySettings(), logger)
+	case "otlp_http":
+		gzip, err := otlpexport.Gzip(cfg.Compression)
+		if err != nil {
+			return nil, err
+		}
+		var clientOpts []otlptracehttp.Option
+		if strings.Contains(cfg.Endpoint, "://") {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		} else if cfg.Endpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.URLPath != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithURLPath(cfg.URLPath))
+		}
+		if cfg.Insecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			clientOpts = append(clientOpts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		if gzip {
+			clientOpts = append(clientOpts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+		}
+		tlsCfg, err := otlpexport.TLSConfig(cfg.TLS, cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		clientOpts = append(clientOpts,
+			otlptracehttp.WithHTTPClient(otlpexport.NewHTTPClient(cfg.ExportTimeout, tlsCfg)),
+			otlptracehttp.WithRetry(otlptracehttp.RetryConfig{Enabled: false}),
+		)
+		return newRetryingExporter(ctx, otlptracehttp.NewClient(clientOpts...), otlpexport.DefaultRetrySettings(), logger)
 	case "stdout":
 		return stdouttrace.New(stdouttrace.WithPrettyPrint())
 	default:
@@ -0,0 +1,87 @@
+package tracing_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/bionicotaku/lingo-utils/observability/internal/otlptest"
+	tracing "github.com/bionicotaku/lingo-utils/observability/tracing"
+)
+
+func TestInitOTLPHTTP(t *testing.T) {
+	cases := []struct {
+		name      string
+		collector func(t *testing.T) *otlptest.Collector
+		caFile    bool
+		wantErr   string
+	}{
+		{name: "plaintext", collector: func(t *testing.T) *otlptest.Collector { return otlptest.NewCollector(t) }},
+		{name: "custom ca", collector: func(t *testing.T) *otlptest.Collector { return otlptest.NewTLSCollector(t) }, caFile: true},
+		// 证书不受信任属于永久失败，不应进入一分钟的重试退避。
+		{name: "untrusted ca", collector: func(t *testing.T) *otlptest.Collector { return otlptest.NewTLSCollector(t) }, wantErr: "otel exporter permanent failure"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			collector := tc.collector(t)
+			t.Cleanup(func() { otel.SetTracerProvider(nooptrace.NewTracerProvider()) })
+
+			cfg := tracing.Config{
+				Exporter:    "otlp_http",
+				Endpoint:    collector.URL,
+				URLPath:     "/otlp/v1/traces",
+				Headers:     map[string]string{"Authorization": "Basic dGVzdA=="},
+				Compression: "gzip",
+			}
+			if tc.caFile {
+				cfg.CAFile = collector.CAFile(t)
+			}
+			var buf strings.Builder
+			shutdown, err := tracing.Init(context.Background(), cfg, tracing.WithLogger(log.NewStdLogger(&buf)))
+			require.NoError(t, err)
+
+			_, span := otel.Tracer("test").Start(context.Background(), "op")
+			span.End()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			// 批处理器不把导出错误传回 Shutdown；永久失败只体现在日志里，且不会耗尽 ctx。
+			require.NoError(t, shutdown(ctx))
+			if tc.wantErr != "" {
+				require.Contains(t, buf.String(), tc.wantErr)
+				require.Empty(t, collector.Requests())
+				return
+			}
+
+			reqs := collector.Requests()
+			require.Len(t, reqs, 1)
+			require.Equal(t, "/otlp/v1/traces", reqs[0].Path)
+			require.Equal(t, "gzip", reqs[0].Encoding)
+			require.Equal(t, "Basic dGVzdA==", reqs[0].Auth)
+		})
+	}
+}
+
+func TestInitOTLPHTTPRejectsUnknownCompression(t *testing.T) {
+	_, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    "otlp_http",
+		Compression: "zstd",
+	}, tracing.WithLogger(log.NewStdLogger(testWriter{t})))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported compression")
+}
+
+func TestInitOTLPHTTPRejectsMissingCAFile(t *testing.T) {
+	_, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter: "otlp_http",
+		CAFile:   "/nonexistent/ca.pem",
+	}, tracing.WithLogger(log.NewStdLogger(testWriter{t})))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "read ca file")
+}
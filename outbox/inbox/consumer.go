@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/bionicotaku/lingo-utils/gclog"
 	"github.com/bionicotaku/lingo-utils/gcpubsub"
     "github.com/bionicotaku/lingo-utils/outbox/store"
 	"github.com/bionicotaku/lingo-utils/txmanager"
@@ -45,7 +47,7 @@ func NewConsumer[T any](sub gcpubsub.Subscriber, store *store.Repository, tx txm
 	if opts.MaxConcurrency <= 0 {
 		opts.MaxConcurrency = 4
 	}
-	helper := log.NewHelper(logger)
+	helper := log.NewHelper(log.With(logger, "logger", "outbox.inbox"))
 	return &Consumer[T]{
 		subscriber: sub,
 		store:      store,
@@ -76,6 +78,21 @@ func (c *Consumer[T]) Run(ctx context.Context) error {
 	})
 }
 
+// Stop 停止拉取新消息并等待在途事务完成，超时后剩余消息会被 Nack。
+func (c *Consumer[T]) Stop(ctx context.Context) error {
+	if c.subscriber == nil {
+		return nil
+	}
+	return c.subscriber.Stop(ctx)
+}
+
+const (
+	// EventIDLabel 是处理单条 inbox 消息期间写入日志 labels 的键。
+	EventIDLabel = "inbox_event_id"
+	// OperationProducer 是 inbox 消息在 logging.googleapis.com/operation 中的 producer。
+	OperationProducer = "lingo-utils/outbox/inbox"
+)
+
 var (
 	errMissingEventID   = errors.New("inbox consumer: missing event_id attribute")
 	errMissingEventType = errors.New("inbox consumer: missing event_type attribute")
@@ -91,6 +108,33 @@ func (c *Consumer[T]) handleMessage(ctx context.Context, msg *gcpubsub.Message)
 		return err
 	}
 
+	// 处理该消息期间的日志（含 handler 内部）归入同一 Cloud Logging operation。
+	// 重投会复用 event ID，operation ID 追加投递次数（未配置死信时为随机后缀），保证每次投递各自首尾完整。
+	eventID := inboxMsg.EventID.String()
+	ctx = gclog.ContextWithLabels(ctx, map[string]string{EventIDLabel: eventID})
+	ctx = gclog.StartOperation(ctx, deliveryOperationID(eventID, msg.DeliveryAttempt), OperationProducer)
+
+	start := c.clock()
+	err = c.process(ctx, msg, inboxMsg)
+	fields := []any{"msg", "inbox message finished", "event_id", eventID, "elapsed_ms", c.clock().Sub(start).Milliseconds()}
+	if err != nil {
+		c.log.WithContext(gclog.EndOperation(ctx)).Warnw(append(fields, "error", err)...)
+		return err
+	}
+	// 成功路径按 DEBUG 收尾，只为携带 operation.last，不增加每条事件的 INFO 量。
+	c.log.WithContext(gclog.EndOperation(ctx)).Debugw(fields...)
+	return nil
+}
+
+// deliveryOperationID 为单次投递生成 operation ID。
+func deliveryOperationID(eventID string, attempt int) string {
+	if attempt > 0 {
+		return eventID + "/" + strconv.Itoa(attempt)
+	}
+	return eventID + "/" + uuid.NewString()
+}
+
+func (c *Consumer[T]) process(ctx context.Context, msg *gcpubsub.Message, inboxMsg store.InboxMessage) error {
 	return c.txManager.WithinTx(ctx, txmanager.TxOptions{}, func(txCtx context.Context, sess txmanager.Session) error {
 		if err := c.store.RecordInboxEvent(txCtx, sess, inboxMsg); err != nil {
 			return fmt.Errorf("record inbox event: %w", err)
@@ -70,6 +70,14 @@ func (r *Runner[T]) Run(ctx context.Context) error {
 	return r.consumer.Run(ctx)
 }
 
+// Stop 优雅停止消费循环，等待在途消息处理完成直至 ctx 截止。
+func (r *Runner[T]) Stop(ctx context.Context) error {
+	if r == nil || r.consumer == nil {
+		return nil
+	}
+	return r.consumer.Stop(ctx)
+}
+
 // WithClock 暴露测试辅助注入。
 func (r *Runner[T]) WithClock(clock func() time.Time) {
 	if r == nil || r.consumer == nil {
@@ -21,7 +21,8 @@ type Runner struct {
 
 // RunnerParams 描述构建 Runner 所需的依赖。
 type RunnerParams struct {
-	Store     *store.Repository
+	Store *store.Repository
+	// Publisher 可直接传入 *gcpubsub.Router，按 event_type / aggregate_type 属性路由到不同 Topic。
 	Publisher gcpubsub.Publisher
 	Config    config.PublisherConfig
 	Logger    log.Logger
@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/bionicotaku/lingo-utils/gclog"
 	"github.com/bionicotaku/lingo-utils/gcpubsub"
 	"github.com/bionicotaku/lingo-utils/outbox/store"
 	"github.com/go-kratos/kratos/v2/log"
@@ -28,6 +29,13 @@ const (
 	defaultLockTTL        = 2 * time.Minute
 )
 
+const (
+	// BatchIDLabel 是每个发布批次写入日志 labels 的键。
+	BatchIDLabel = "outbox_batch_id"
+	// OperationProducer 是发布批次在 logging.googleapis.com/operation 中的 producer。
+	OperationProducer = "lingo-utils/outbox/publisher"
+)
+
 // Config 定义 Outbox 发布任务运行参数。
 type Config struct {
 	BatchSize      int
@@ -73,7 +81,7 @@ func NewTask(repo *store.Repository, pub Publisher, cfg Config, logger log.Logge
 
 	var helper *log.Helper
 	if logEnabled {
-		helper = log.NewHelper(logger)
+		helper = log.NewHelper(log.With(logger, "logger", "outbox.publisher"))
 	} else {
 		helper = log.NewHelper(log.NewStdLogger(io.Discard))
 	}
@@ -148,6 +156,11 @@ func (t *Task) drain(ctx context.Context) error {
 		return nil
 	}
 
+	// 同一批次的日志共享 batch ID 与 Cloud Logging operation，便于聚合查看。
+	batchID := uuid.NewString()
+	ctx = gclog.ContextWithLabels(ctx, map[string]string{BatchIDLabel: batchID})
+	ctx = gclog.StartOperation(ctx, batchID, OperationProducer)
+
 	batchFields := []any{"msg", "outbox batch", "count", len(events)}
 	if backlogErr == nil {
 		batchFields = append(batchFields, "backlog_before", backlogBefore)
@@ -155,42 +168,18 @@ func (t *Task) drain(ctx context.Context) error {
 	t.log.WithContext(ctx).Infow(batchFields...)
 
 	var successCount, failureCount int32
-	workers := t.cfg.Workers
-	if workers <= 1 {
-		for _, event := range events {
-			if ctx.Err() != nil {
-				return ctx.Err()
-			}
-			if err := t.publishOnce(ctx, event); err != nil {
-				atomic.AddInt32(&failureCount, 1)
-			} else {
-				atomic.AddInt32(&successCount, 1)
-			}
-		}
-	} else {
-		sem := make(chan struct{}, workers)
-		grp, grpCtx := errgroup.WithContext(ctx)
-		for _, evt := range events {
-			event := evt
-			sem <- struct{}{}
-			grp.Go(func() error {
-				defer func() { <-sem }()
-				if err := t.publishOnce(grpCtx, event); err != nil {
-					atomic.AddInt32(&failureCount, 1)
-				} else {
-					atomic.AddInt32(&successCount, 1)
-				}
-				return nil
-			})
-		}
-		if err := grp.Wait(); err != nil && !errors.Is(err, context.Canceled) {
-			return err
-		}
-	}
+	publishErr := t.publishBatch(ctx, events, &successCount, &failureCount)
 
-	backlogAfter, backlogAfterErr := t.refreshBacklog(ctx)
-	if backlogAfterErr != nil {
-		t.log.WithContext(ctx).Warnw("msg", "outbox backlog recount failed", "error", backlogAfterErr)
+	// 无论批次正常结束还是被取消，都通过 EndOperation 写出最后一条日志，保证操作闭合。
+	var (
+		backlogAfter    int64
+		backlogAfterErr error
+	)
+	if publishErr == nil {
+		backlogAfter, backlogAfterErr = t.refreshBacklog(ctx)
+		if backlogAfterErr != nil {
+			t.log.WithContext(ctx).Warnw("msg", "outbox backlog recount failed", "error", backlogAfterErr)
+		}
 	}
 
 	elapsed := t.clock().Sub(now)
@@ -204,17 +193,60 @@ func (t *Task) drain(ctx context.Context) error {
 	if backlogErr == nil {
 		fields = append(fields, "backlog_before", backlogBefore)
 	}
-	if backlogAfterErr == nil {
+	if publishErr != nil {
+		fields = append(fields, "error", publishErr)
+	} else if backlogAfterErr == nil {
 		fields = append(fields, "backlog_after", backlogAfter)
 	}
-	t.log.WithContext(ctx).Infow(fields...)
+	t.log.WithContext(gclog.EndOperation(ctx)).Infow(fields...)
 
+	if publishErr != nil {
+		return publishErr
+	}
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 	return nil
 }
 
+// publishBatch 按配置的并发度发布一批事件，ctx 取消时提前返回。
+func (t *Task) publishBatch(ctx context.Context, events []store.Event, successCount, failureCount *int32) error {
+	workers := t.cfg.Workers
+	if workers <= 1 {
+		for _, event := range events {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := t.publishOnce(ctx, event); err != nil {
+				atomic.AddInt32(failureCount, 1)
+			} else {
+				atomic.AddInt32(successCount, 1)
+			}
+		}
+		return nil
+	}
+
+	sem := make(chan struct{}, workers)
+	grp, grpCtx := errgroup.WithContext(ctx)
+	for _, evt := range events {
+		event := evt
+		sem <- struct{}{}
+		grp.Go(func() error {
+			defer func() { <-sem }()
+			if err := t.publishOnce(grpCtx, event); err != nil {
+				atomic.AddInt32(failureCount, 1)
+			} else {
+				atomic.AddInt32(successCount, 1)
+			}
+			return nil
+		})
+	}
+	if err := grp.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
 func (t *Task) refreshBacklog(ctx context.Context) (int64, error) {
 	if t.repo == nil {
 		return 0, nil
@@ -229,6 +261,27 @@ func (t *Task) refreshBacklog(ctx context.Context) (int64, error) {
 	return count, nil
 }
 
+// eventAttributes 复制事件头并补齐 event_id / event_type / aggregate_type / aggregate_id，
+// 供 inbox 消费端与 gcpubsub.Router 按属性路由使用；事件头中已有的值优先。
+func eventAttributes(event store.Event) map[string]string {
+	attributes := make(map[string]string, len(event.Headers)+4)
+	for k, v := range event.Headers {
+		attributes[k] = v
+	}
+	defaults := map[string]string{
+		"event_id":       event.EventID.String(),
+		"event_type":     event.EventType,
+		"aggregate_type": event.AggregateType,
+		"aggregate_id":   event.AggregateID.String(),
+	}
+	for k, v := range defaults {
+		if _, ok := attributes[k]; !ok && v != "" {
+			attributes[k] = v
+		}
+	}
+	return attributes
+}
+
 func (t *Task) publishOnce(ctx context.Context, event store.Event) error {
 	if event.LockToken == nil || *event.LockToken != t.lockToken {
 		t.log.WithContext(ctx).Warnw("msg", "outbox lock token mismatch", "event_id", event.EventID, "expected", t.lockToken, "actual", event.LockToken)
@@ -242,10 +295,7 @@ func (t *Task) publishOnce(ctx context.Context, event store.Event) error {
 		defer cancel()
 	}
 
-	attributes := event.Headers
-	if attributes == nil {
-		attributes = map[string]string{}
-	}
+	attributes := eventAttributes(event)
 
 	msg := gcpubsub.Message{
 		Data:            event.Payload,
@@ -0,0 +1,22 @@
+package store
+
+// CallerHeader 记录触发事件的调用方身份（服务账号 email 或 subject）。
+const CallerHeader = "caller"
+
+// WithCaller 将调用方写入 Headers[CallerHeader]；caller 为空或已显式设置时原样返回。
+// 返回的 Message 持有独立的 Headers 副本。调用方通常取自 gcjwt.Caller(ctx)。
+func WithCaller(msg Message, caller string) Message {
+	if caller == "" {
+		return msg
+	}
+	if _, exists := msg.Headers[CallerHeader]; exists {
+		return msg
+	}
+	headers := make(map[string]string, len(msg.Headers)+1)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[CallerHeader] = caller
+	msg.Headers = headers
+	return msg
+}
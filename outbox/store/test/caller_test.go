@@ -0,0 +1,25 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/bionicotaku/lingo-utils/outbox/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCaller(t *testing.T) {
+	original := map[string]string{"trace_id": "abc123"}
+	msg := store.WithCaller(store.Message{Headers: original}, "svc-a@example.com")
+	assert.Equal(t, "svc-a@example.com", msg.Headers[store.CallerHeader])
+	assert.Equal(t, "abc123", msg.Headers["trace_id"])
+	assert.NotContains(t, original, store.CallerHeader, "input headers must not be mutated")
+
+	msg = store.WithCaller(store.Message{}, "svc-a@example.com")
+	assert.Equal(t, "svc-a@example.com", msg.Headers[store.CallerHeader])
+
+	msg = store.WithCaller(store.Message{Headers: map[string]string{store.CallerHeader: "explicit"}}, "svc-a@example.com")
+	assert.Equal(t, "explicit", msg.Headers[store.CallerHeader])
+
+	msg = store.WithCaller(store.Message{}, "")
+	assert.Nil(t, msg.Headers)
+}